@@ -10,6 +10,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -65,6 +66,33 @@ type CronSearchSpec struct {
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// CrawlerState configures a volume that persists between the searches of the CronSearch,
+	// which is mounted into the crawler so it can store state such as cursors, and only emit
+	// the targets that changed since the last search. The volume is deleted along with the
+	// CronSearch, or once this field is removed. Since the volume can only be mounted by one
+	// node at a time, a concurrency policy of "Forbid" is recommended.
+	// +optional
+	CrawlerState *CrawlerStateSpec `json:"crawlerState,omitempty"`
+}
+
+// CrawlerStateSpec configures the PersistentVolumeClaim created to persist crawler
+// state between the searches of a CronSearch. The claim is owned by the CronSearch.
+type CrawlerStateSpec struct {
+	// StorageClassName is the name of the StorageClass used for the claim.
+	// If not set, the cluster's default StorageClass will be used.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size is the amount of storage requested for the claim. Defaults to 64Mi.
+	// +optional
+	// +kubebuilder:default="64Mi"
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// AccessModes are the access modes of the claim. Defaults to ReadWriteOnce.
+	// +optional
+	// +listType=atomic
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
 }
 
 // ConcurrencyPolicy describes how the job will be handled.
@@ -57,6 +57,13 @@ type DownloaderSpec struct {
 	// +listType=map
 	// +listMapKey=name
 	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,15,rep,name=imagePullSecrets"`
+
+	// ActiveDeadlineSeconds is the duration in seconds the downloader may run for
+	// before it is stopped and the [Pipeline] fails with the reason StageTimedOut.
+	// Value must be a positive integer. An empty value means no deadline.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty" protobuf:"varint,4,opt,name=activeDeadlineSeconds"`
 }
 
 type DownloaderStatus struct {
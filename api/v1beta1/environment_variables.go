@@ -56,11 +56,26 @@ const (
 	EnvVarResultsDir EnvironmentVariableName = "OCULAR_RESULTS_DIR"
 	// EnvVarMetadataDir is the environment variable name for the metadata directory.
 	// It specifies the directory path where target metadata files are stored within the container.
-	// This variable is only set for [ProfileSpec.Containers] and not for [Uploader] containers.
+	// This variable is set for both [ProfileSpec.Containers] and [Uploader] containers.
 	EnvVarMetadataDir EnvironmentVariableName = "OCULAR_METADATA_DIR"
 	// EnvVarUploaderName is the environment variable name for the uploader name.
 	// It specifies the name of the [Uploader] resource used in the pipeline to upload analysis results.
 	EnvVarUploaderName EnvironmentVariableName = "OCULAR_UPLOADER_NAME"
+	// EnvVarFailedScanners is the environment variable name for the scanners that failed.
+	// It is a comma separated list of the container names of each scanner that exited with a non-zero
+	// exit code, and is empty if all scanners succeeded. This variable is only set for [Uploader] containers,
+	// which will only be run with failed scanners if allowed by the profile's [ScannerFailurePolicy].
+	EnvVarFailedScanners EnvironmentVariableName = "OCULAR_FAILED_SCANNERS"
+	// EnvVarScanSummaryPath is the environment variable name for the path of the scan summary.
+	// It specifies the path of a JSON file, written once all scanners have completed, with the exit code
+	// and duration of each scanner and whether each artifact exists. This variable is only set for [Uploader]
+	// containers, and the file can be read with the helpers in the ocular runtime package.
+	EnvVarScanSummaryPath EnvironmentVariableName = "OCULAR_SCAN_SUMMARY_PATH"
+	// EnvVarArtifactManifestPath is the environment variable name for the path of the artifact manifest.
+	// It specifies the path of a JSON file, written once all scanners have completed, listing each artifact
+	// and metadata file to upload with its kind, digest, size and the scanner that produced it. This variable
+	// is only set for [Uploader] containers, and the file can be read with the helpers in the ocular runtime package.
+	EnvVarArtifactManifestPath EnvironmentVariableName = "OCULAR_ARTIFACT_MANIFEST_PATH"
 
 	/* Search related environment variables */
 
@@ -70,6 +85,11 @@ const (
 	// EnvVarCrawlerName is the environment variable name for the crawler name.
 	EnvVarCrawlerName EnvironmentVariableName = "OCULAR_CRAWLER_NAME"
 
+	// EnvVarCrawlerStateDir is the environment variable that contains the path to a
+	// directory persisted between searches, which crawlers can use to store state such as
+	// the last target emitted. It is only set for searches with [SearchSpec.CrawlerStateClaimName].
+	EnvVarCrawlerStateDir EnvironmentVariableName = "OCULAR_CRAWLER_STATE_DIR"
+
 	// EnvVarPipelineFIFO is the environment variable that contains the path
 	// to a named pipe (or FIFO) that will read JSON targets and automatically start pipelines
 	// with the spec from the pipeline template in the search spec.
@@ -80,6 +100,12 @@ const (
 	// searches with the same scheduler configuration (pipeline template and interval)
 	EnvVarSearchFIFO EnvironmentVariableName = "OCULAR_SEARCH_FIFO"
 
+	// EnvVarSchedulerSocket is the environment variable that contains the path
+	// to a unix domain socket that accepts the same targets and crawler references as
+	// [EnvVarPipelineFIFO] and [EnvVarSearchFIFO], responding to each with the name of the
+	// created pipeline or search, or the reason it could not be created.
+	EnvVarSchedulerSocket EnvironmentVariableName = "OCULAR_SCHEDULER_SOCKET"
+
 	// EnvVarPipelineTemplatePath is the name of the environment variable that contains
 	// the path to the JSON data of the pipeline template to use when creating pipelines
 	// from a search
@@ -89,6 +115,30 @@ const (
 	// creating pipelines
 	EnvVarPipelineSchedulerIntervalSeconds EnvironmentVariableName = "OCULAR_PIPELINE_SCHEDULER_INTERVAL_SEC"
 
+	// EnvVarPipelineSchedulerMaxActivePipelines is the maximum number of
+	// active pipelines the scheduler should allow. Empty string means no limit.
+	EnvVarPipelineSchedulerMaxActivePipelines EnvironmentVariableName = "OCULAR_PIPELINE_SCHEDULER_MAX_ACTIVE_PIPELINES"
+
+	// EnvVarPipelineSchedulerMaxActiveSearches is the maximum number of
+	// active searches the scheduler should allow. Empty string means no limit.
+	EnvVarPipelineSchedulerMaxActiveSearches EnvironmentVariableName = "OCULAR_PIPELINE_SCHEDULER_MAX_ACTIVE_SEARCHES"
+
+	// EnvVarPipelineDeduplicationPolicy is the [PipelineDeduplicationPolicy] the scheduler
+	// should apply before creating a pipeline for a target.
+	EnvVarPipelineDeduplicationPolicy EnvironmentVariableName = "OCULAR_PIPELINE_DEDUPLICATION_POLICY"
+
+	// EnvVarPipelineDeduplicationWindowSeconds is the window in seconds of existing pipelines
+	// the scheduler should consider when deduplicating. Empty string means no window.
+	EnvVarPipelineDeduplicationWindowSeconds EnvironmentVariableName = "OCULAR_PIPELINE_DEDUPLICATION_WINDOW_SEC"
+
+	// EnvVarPipelineOverridePolicy is the JSON of the [PipelineOverridePolicy] the scheduler
+	// validates the overrides of each target against. If empty, no overrides are allowed.
+	EnvVarPipelineOverridePolicy EnvironmentVariableName = "OCULAR_PIPELINE_OVERRIDE_POLICY"
+
+	// EnvVarSearchSuccessPolicy is the JSON of the [SearchSuccessPolicy] of the search,
+	// which the scheduler sets on the searches it creates. Empty if the search has no policy.
+	EnvVarSearchSuccessPolicy EnvironmentVariableName = "OCULAR_SEARCH_SUCCESS_POLICY"
+
 	// internal environment variables  //
 
 	// EnvVarProcessDir is the environment variable with the name of the process
@@ -107,6 +157,34 @@ const (
 	// names for each scan container in the pod
 	EnvVarScanContainerNames EnvironmentVariableName = "OCULAR_SCAN_CONTAINER_NAMES"
 
+	// EnvVarScannerFailurePolicy is the [ScannerFailurePolicy] of the profile, used
+	// by the sidecar to determine if the uploaders should run after scanners fail
+	EnvVarScannerFailurePolicy EnvironmentVariableName = "OCULAR_SCANNER_FAILURE_POLICY"
+
+	// EnvVarTimeoutSeconds is the deadline in seconds of a container
+	// wrapped by the sidecar, after which the sidecar signals it to stop
+	EnvVarTimeoutSeconds EnvironmentVariableName = "OCULAR_TIMEOUT_SECONDS"
+
+	// EnvVarArtifactChecks is a JSON encoded list of the [ArtifactCheck] of the
+	// profile, with absolute paths, run by the sidecar before the uploaders start
+	EnvVarArtifactChecks EnvironmentVariableName = "OCULAR_ARTIFACT_CHECKS"
+
+	// EnvVarS3Bucket, EnvVarS3Endpoint, EnvVarS3Region, EnvVarS3ForcePathStyle and
+	// EnvVarS3KeyTemplate are the fields of the [S3UploaderSpec] of an uploader,
+	// read by the sidecar when uploading to an S3 compatible object store
+	EnvVarS3Bucket         EnvironmentVariableName = "OCULAR_S3_BUCKET"
+	EnvVarS3Endpoint       EnvironmentVariableName = "OCULAR_S3_ENDPOINT"
+	EnvVarS3Region         EnvironmentVariableName = "OCULAR_S3_REGION"
+	EnvVarS3ForcePathStyle EnvironmentVariableName = "OCULAR_S3_FORCE_PATH_STYLE"
+	EnvVarS3KeyTemplate    EnvironmentVariableName = "OCULAR_S3_KEY_TEMPLATE"
+
+	// EnvVarAWSAccessKeyID, EnvVarAWSSecretAccessKey and EnvVarAWSSessionToken are the credentials
+	// used by the sidecar to sign requests to an S3 compatible object store, and the keys of the
+	// secret referenced by [S3UploaderSpec.CredentialsSecretRef]
+	EnvVarAWSAccessKeyID     EnvironmentVariableName = "AWS_ACCESS_KEY_ID"
+	EnvVarAWSSecretAccessKey EnvironmentVariableName = "AWS_SECRET_ACCESS_KEY"
+	EnvVarAWSSessionToken    EnvironmentVariableName = "AWS_SESSION_TOKEN"
+
 	// EnvVarExtractorPort is the environment variable name for the extractor port.
 	EnvVarExtractorPort EnvironmentVariableName = "OCULAR_EXTRACTOR_PORT"
 
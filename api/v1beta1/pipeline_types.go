@@ -10,6 +10,7 @@ package v1beta1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -35,6 +36,28 @@ const (
 	ProfileLabelKey = Group + "/profile"
 	// DownloaderLabelKey is the label key used to identify pipelines created from a specific downloader.
 	DownloaderLabelKey = Group + "/downloader"
+
+	// TargetHashLabelKey is the label key containing a hash of the target, profile reference
+	// and downloader reference of a pipeline. Pipelines with the same value for this label
+	// will process the same target in the same way. This label is set by the controller
+	// on every pipeline.
+	TargetHashLabelKey = Group + "/targetHash"
+
+	// PipelineStageLabelKey is the label key used to identify which [PipelineStage] a pod runs
+	// for pipelines using [PipelineExecutionModeMultiPod].
+	PipelineStageLabelKey = Group + "/stage"
+
+	// ReceiverLabelKey is the label key used to identify the ingestion receiver
+	// of the controller that created a pipeline from a received payload.
+	ReceiverLabelKey = Group + "/receiver"
+
+	// ReuploadAnnotation is the annotation used to request the uploaders of a completed
+	// pipeline be run again against its saved results, without downloading or scanning
+	// the target again. The value of the annotation is ignored, and the annotation is removed
+	// by the controller once the request is handled. This is only supported for pipelines using
+	// [PipelineExecutionModeMultiPod], since the results are kept in the pipeline's
+	// PersistentVolumeClaim until the pipeline is deleted.
+	ReuploadAnnotation = Group + "/reupload"
 )
 
 type PipelineSpec struct {
@@ -96,6 +119,125 @@ type PipelineSpec struct {
 	// entire pod, allowing resource sharing among containers in a pod.
 	// +optional
 	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Priority is the priority of the pipeline when waiting in a [PipelineQueue]
+	// or [ClusterPipelineQueue]. Pending pipelines with a higher priority are admitted
+	// before pipelines with a lower priority, and pipelines with the same priority are
+	// admitted in the order they were created. Running pipelines are never stopped in favor
+	// of higher priority pipelines. If not set, the priority is 0.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass that will be set for the scan pod.
+	// This allows the Kubernetes scheduler to order and preempt scan pods.
+	// If not set, the cluster's default priority will be used.
+	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// RetryPolicy configures if and how the pipeline should be retried when the scan pod fails.
+	// If not set, the pipeline will fail as soon as the scan pod fails.
+	// +optional
+	RetryPolicy *PipelineRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// ExecutionMode determines how the stages of the pipeline are run.
+	// Defaults to [PipelineExecutionModeSinglePod].
+	// +optional
+	// +kubebuilder:default=SinglePod
+	ExecutionMode PipelineExecutionMode `json:"executionMode,omitempty"`
+
+	// Storage configures the PersistentVolumeClaim shared between the pods of the
+	// pipeline. This is only used when ExecutionMode is [PipelineExecutionModeMultiPod].
+	// +optional
+	Storage *PipelineStorage `json:"storage,omitempty"`
+}
+
+// PipelineExecutionMode determines how the stages of a pipeline are run.
+// +enum
+// +kubebuilder:validation:Enum=SinglePod;MultiPod
+type PipelineExecutionMode string
+
+const (
+	// PipelineExecutionModeSinglePod runs the downloader, scanners and uploaders in a single scan pod.
+	// The target, results and metadata directories are stored in EmptyDir volumes of the pod.
+	PipelineExecutionModeSinglePod PipelineExecutionMode = "SinglePod"
+	// PipelineExecutionModeMultiPod runs each stage in its own pod, one after another.
+	// The target, results and metadata directories are stored in a PersistentVolumeClaim
+	// created for the pipeline, configured by [PipelineSpec.Storage]. This allows each stage to
+	// request only the resources it needs, and failed stages to be retried without
+	// running the stages before it again.
+	PipelineExecutionModeMultiPod PipelineExecutionMode = "MultiPod"
+)
+
+// PipelineStorage configures the PersistentVolumeClaim created for a pipeline
+// using [PipelineExecutionModeMultiPod]. The claim is owned by the pipeline
+// and is deleted along with it.
+type PipelineStorage struct {
+	// StorageClassName is the name of the StorageClass used for the claim.
+	// If not set, the cluster's default StorageClass will be used.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size is the amount of storage requested for the claim.
+	// It must be large enough to hold the target, results and metadata. Defaults to 1Gi.
+	// +optional
+	// +kubebuilder:default="1Gi"
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// AccessModes are the access modes of the claim. Since the stages of a pipeline
+	// run one after another, this defaults to ReadWriteOnce.
+	// +optional
+	// +listType=atomic
+	AccessModes []v1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+// PipelineStage is a stage of a pipeline.
+// +enum
+// +kubebuilder:validation:Enum=Download;Scan;Upload
+type PipelineStage string
+
+const (
+	// PipelineStageDownload is the stage where the downloader retrieves the target.
+	PipelineStageDownload PipelineStage = "Download"
+	// PipelineStageScan is the stage where the scanners of the profile analyze the target.
+	PipelineStageScan PipelineStage = "Scan"
+	// PipelineStageUpload is the stage where the uploaders of the profile upload the results.
+	PipelineStageUpload PipelineStage = "Upload"
+)
+
+// PipelineRetryPolicy configures how a failed pipeline is retried.
+// When a retry is triggered the scan pod is recreated, running
+// every stage again. For pipelines using [PipelineExecutionModeMultiPod],
+// only the pods for the failed stage and the stages after it are recreated.
+type PipelineRetryPolicy struct {
+	// MaxRetries is the maximum number of times the scan pod will be
+	// recreated after it fails.
+	// +required
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	MaxRetries int32 `json:"maxRetries"`
+
+	// BackoffSeconds is the number of seconds to wait after a failure before
+	// recreating the scan pod. The wait doubles for each subsequent retry.
+	// Defaults to 10.
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	BackoffSeconds *int32 `json:"backoffSeconds,omitempty"`
+
+	// MaxBackoffSeconds is the maximum number of seconds to wait before recreating
+	// the scan pod, regardless of the number of retries. Defaults to 600.
+	// +optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=0
+	MaxBackoffSeconds *int32 `json:"maxBackoffSeconds,omitempty"`
+
+	// Stages is the list of stages that should be retried when they fail.
+	// A failure in a stage not listed will fail the pipeline immediately.
+	// If omitted, failures in any stage are retried.
+	// +optional
+	// +listType=set
+	Stages []PipelineStage `json:"stages,omitempty"`
 }
 
 // PipelinePhase is a label for the condition of a pipeline at the current time.
@@ -116,6 +258,10 @@ const (
 	// PipelineSucceeded means that all containers in the pipeline have terminated in success
 	// (exited with a zero exit code).
 	PipelineSucceeded PipelinePhase = "Succeeded"
+	// PipelinePartiallySucceeded means that the downloader and all uploaders have terminated in success,
+	// but one or more scanners failed and the profile's [ScannerFailurePolicyUploadOnPartialFailure]
+	// allowed the results of the remaining scanners to be uploaded.
+	PipelinePartiallySucceeded PipelinePhase = "PartiallySucceeded"
 	// PipelineFailed means that one or more containers in the pipeline
 	// (downloader, uploader, scanner) have terminated in a failure
 	// (exited with a non-zero exit code or was stopped by the system).
@@ -135,6 +281,13 @@ const (
 	// The absence of this condition indicates that the scan pod has not been created yet.
 	PipelineScanPodCreatedConditionType = "PipelineScanPodCreated"
 
+	// PipelineDownloadPodCreatedConditionType is the condition type used when the download pod for a pipeline
+	// using [PipelineExecutionModeMultiPod] has been created.
+	// If this condition is true, it indicates that the download pod has been successfully created.
+	// The absence of this condition indicates that the download pod has not been created yet (or won't be
+	// created if the pipeline uses [PipelineExecutionModeSinglePod]).
+	PipelineDownloadPodCreatedConditionType = "PipelineDownloadPodCreated"
+
 	// PipelineUploadPodCreatedConditionType is the condition type used when the upload pod for a pipeline has been created.
 	// If this condition is true, it indicates that the upload pod has been successfully created.
 	// If this condition is false, it indicates that there was an error creating the upload pod.
@@ -147,6 +300,29 @@ const (
 	// If this condition is false, it indicates that the pipeline has completed, but with a failure.
 	// The absence of this condition indicates that the pipeline is still in progress.
 	PipelineCompletedSuccessfullyConditionType = "PipelineCompletedSuccessful"
+
+	// PipelineRetryingConditionType is the condition type used when a pipeline failed and will be
+	// retried according to its [PipelineRetryPolicy].
+	// If this condition is true, the scan pod failed and will be recreated once the backoff has passed.
+	// If this condition is false, the scan pod has been recreated.
+	// The absence of this condition indicates that the pipeline has not been retried.
+	PipelineRetryingConditionType = "Retrying"
+
+	// PipelineReuploadingConditionType is the condition type used when the uploaders of a completed
+	// pipeline are run again after the [ReuploadAnnotation] was added to the pipeline.
+	// If this condition is true, the upload pod is running again and the pipeline is no longer complete.
+	// If this condition is false, the re-upload has finished or could not be started, see the reason for details.
+	// The absence of this condition indicates that a re-upload was never requested.
+	PipelineReuploadingConditionType = "Reuploading"
+
+	// PipelineArtifactsValidConditionType is the condition type used once the sidecar has checked
+	// the artifacts of the pipeline before running the uploaders.
+	// If this condition is true, every artifact exists and passed its [ArtifactCheck].
+	// If this condition is false, one or more artifacts are missing or failed their check,
+	// see [PipelineStatus.Artifacts] for details. These artifacts are not passed to the uploaders.
+	// The absence of this condition indicates that the artifacts have not been checked,
+	// which is always the case for a profile with no uploaders.
+	PipelineArtifactsValidConditionType = "ArtifactsValid"
 )
 
 // PipelineStageStatus represents the status of a specific (downloader, uploader, scanners)
@@ -184,6 +360,109 @@ type PipelineStageStatuses struct {
 	UploadStatus PipelineStageStatus `json:"uploadStatus" description:"The current status of the upload stage."`
 }
 
+// PipelineContainerStatus is the result of a single downloader, scanner or uploader
+// container of a pipeline.
+type PipelineContainerStatus struct {
+	// Name is the name of the container in the pipeline's pod,
+	// which is prefixed by the stage of the container.
+	// +required
+	Name string `json:"name" description:"The name of the container in the pipeline's pod."`
+
+	// Stage is the stage of the pipeline the container runs in.
+	// +required
+	Stage PipelineStage `json:"stage" description:"The stage of the pipeline the container runs in."`
+
+	// Image is the image the container is running.
+	// +optional
+	Image string `json:"image,omitempty" description:"The image the container is running."`
+
+	// PodName is the name of the pod the container ran in.
+	// +optional
+	PodName string `json:"podName,omitempty" description:"The name of the pod the container ran in."`
+
+	// ExitCode is the exit code of the container, nil if the container has not terminated.
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty" description:"The exit code of the container, nil if the container has not terminated."`
+
+	// Reason is a brief reason for the current state of the container, such as
+	// 'OOMKilled' or 'Error' once terminated, or 'ImagePullBackOff' while waiting.
+	// +optional
+	Reason string `json:"reason,omitempty" description:"A brief reason for the current state of the container."`
+
+	// StartedAt is the time the container started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty" description:"The time the container started."`
+
+	// FinishedAt is the time the container terminated.
+	// +optional
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty" description:"The time the container terminated."`
+}
+
+// PipelineArtifactStatus is the result of checking an artifact or
+// metadata file of a pipeline before it is passed to the uploaders.
+type PipelineArtifactStatus struct {
+	// Path is the absolute path of the artifact in the uploader containers.
+	// +required
+	Path string `json:"path" description:"The absolute path of the artifact in the uploader containers."`
+
+	// Present is true if the artifact exists.
+	// +required
+	Present bool `json:"present" description:"True if the artifact exists."`
+
+	// SizeBytes is the size of the artifact in bytes.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty" description:"The size of the artifact in bytes."`
+
+	// Error is the reason the artifact failed its check, empty if the check passed.
+	// +optional
+	Error string `json:"error,omitempty" description:"The reason the artifact failed its check, empty if the check passed."`
+
+	// Findings is the summary of the findings in the artifact,
+	// only set for SARIF and CycloneDX artifacts.
+	// +optional
+	Findings *ArtifactFindings `json:"findings,omitempty" description:"The summary of the findings in the artifact."`
+}
+
+// ArtifactFindings is a summary of the findings in a SARIF or CycloneDX artifact.
+// Severities are normalized to one of critical, high, medium, low, info or unknown.
+// For SARIF results the security-severity property is used when set, otherwise the level
+// of the result is mapped, with error as high, warning as medium, note as low and none as info.
+type ArtifactFindings struct {
+	// Total is the number of results in a SARIF log, or vulnerabilities in a CycloneDX BOM.
+	// +required
+	Total int32 `json:"total" description:"The number of results or vulnerabilities in the artifact."`
+
+	// BySeverity is the number of findings for each severity. Like [ArtifactFindings.ByRule],
+	// it is omitted if the findings of all artifacts are too large to be reported.
+	// +optional
+	BySeverity map[string]int32 `json:"bySeverity,omitempty" description:"The number of findings for each severity."`
+
+	// ByRule is the number of results for each rule of a SARIF log,
+	// limited to the rules with the most results. It is omitted if the
+	// findings of all artifacts are too large to be reported by the sidecar.
+	// +optional
+	ByRule map[string]int32 `json:"byRule,omitempty" description:"The number of results for each rule, limited to the rules with the most results."`
+
+	// Components is the number of components in a CycloneDX BOM, including nested components.
+	// +optional
+	Components int32 `json:"components,omitempty" description:"The number of components in a CycloneDX BOM."`
+}
+
+// PipelineFindings is the total of the [ArtifactFindings] of each artifact of a pipeline.
+type PipelineFindings struct {
+	// Total is the number of findings across all artifacts.
+	// +required
+	Total int32 `json:"total" description:"The number of findings across all artifacts."`
+
+	// BySeverity is the number of findings across all artifacts for each severity.
+	// +optional
+	BySeverity map[string]int32 `json:"bySeverity,omitempty" description:"The number of findings across all artifacts for each severity."`
+
+	// Components is the number of components across all CycloneDX artifacts.
+	// +optional
+	Components int32 `json:"components,omitempty" description:"The number of components across all CycloneDX artifacts."`
+}
+
 type PipelineStatus struct {
 	// Conditions latest available observations of an object's current state. When a Search
 	// fails, one of the conditions will have type [FailedConditionType] and status true.
@@ -212,6 +491,40 @@ type PipelineStatus struct {
 	// StageStatuses represents the current status of each stage in the pipeline.
 	// +optional
 	StageStatuses PipelineStageStatuses `json:"stageStatuses,omitempty,omitzero" description:"The current status of each stage in the pipeline."`
+
+	// Attempts is the number of times the pipeline has been attempted, starting at 1
+	// when the first pod is created. This will be greater than 1 if the pipeline
+	// was retried by its [PipelineRetryPolicy].
+	// +optional
+	Attempts int32 `json:"attempts,omitempty" description:"The number of times the pipeline has been attempted."`
+
+	// Reuploads is the number of times the uploaders of the completed pipeline were run
+	// again after the [ReuploadAnnotation] was added. Reuploads are not counted in Attempts,
+	// so they do not use up the retries of the [PipelineRetryPolicy].
+	// +optional
+	Reuploads int32 `json:"reuploads,omitempty" description:"The number of times the uploaders of the pipeline were run again."`
+
+	// ContainerStatuses are the results of the downloader, each scanner and each uploader
+	// container of the pipeline, ordered by stage. If the pipeline was retried, the
+	// statuses are from the latest pod for each container.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ContainerStatuses []PipelineContainerStatus `json:"containerStatuses,omitempty" description:"The results of each container of the pipeline."`
+
+	// Artifacts are the results of checking each artifact and metadata file
+	// once all scanners have completed, before the uploaders are started.
+	// +optional
+	// +listType=map
+	// +listMapKey=path
+	Artifacts []PipelineArtifactStatus `json:"artifacts,omitempty" description:"The results of checking each artifact before it is uploaded."`
+
+	// Findings is the summary of the findings across all SARIF and CycloneDX artifacts,
+	// as marked by the [ArtifactCheck] of the profile. See [PipelineStatus.Artifacts]
+	// for the findings of each artifact. Artifacts are checked by the sidecar of the
+	// uploaders, so findings are only computed for profiles with at least one uploader.
+	// +optional
+	Findings *PipelineFindings `json:"findings,omitempty" description:"The summary of the findings across all SARIF and CycloneDX artifacts, only computed for profiles with uploaders."`
 }
 
 // +kubebuilder:object:root=true
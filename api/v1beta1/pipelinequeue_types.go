@@ -0,0 +1,101 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// PipelineQueuedConditionType is the condition type used when a pipeline is waiting
+	// for a slot in a [PipelineQueue] or [ClusterPipelineQueue] before its scan pod is created.
+	// If this condition is true, the pipeline is waiting in a queue and will remain in the
+	// [PipelinePending] phase. If this condition is false, the pipeline has been admitted.
+	// The absence of this condition indicates that the pipeline never had to wait in a queue.
+	PipelineQueuedConditionType = "Queued"
+)
+
+// PipelineQueueSpec defines the desired state of PipelineQueue
+type PipelineQueueSpec struct {
+	// MaxActivePipelines is the maximum number of pipelines matched by the queue
+	// that can have a scan pod at once. Pipelines that are matched by the queue once
+	// the limit is reached stay in the [PipelinePending] phase until a slot frees up.
+	// Pipelines are admitted in order of their [PipelineSpec.Priority],
+	// and pipelines with the same priority in the order they were created.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MaxActivePipelines int32 `json:"maxActivePipelines"`
+
+	// Selector is a label query over the pipelines limited by this queue.
+	// If omitted, pipelines will not be filtered by their labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Profiles limits the queue to pipelines with a profile reference to
+	// one of the given profile names. If omitted, pipelines will not be filtered
+	// by their profile.
+	// +optional
+	// +listType=set
+	Profiles []string `json:"profiles,omitempty"`
+
+	// Downloaders limits the queue to pipelines with a downloader reference to
+	// one of the given downloader names. This matches references to both Downloaders
+	// and ClusterDownloaders. If omitted, pipelines will not be filtered by their downloader.
+	// +optional
+	// +listType=set
+	Downloaders []string `json:"downloaders,omitempty"`
+}
+
+// PipelineQueueStatus defines the observed state of PipelineQueue.
+type PipelineQueueStatus struct {
+	// Conditions represent the latest available observations of a PipelineQueue's current state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" description:"The latest available observations of a PipelineQueue's current state."`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +genclient
+
+// PipelineQueue is the Schema for the pipelinequeues API.
+// A PipelineQueue limits the number of active pipelines in its namespace.
+type PipelineQueue struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of PipelineQueue
+	// +required
+	Spec PipelineQueueSpec `json:"spec"`
+
+	// status defines the observed state of PipelineQueue
+	// +optional
+	Status PipelineQueueStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// PipelineQueueList contains a list of PipelineQueue
+type PipelineQueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []PipelineQueue `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(SchemeGroupVersion, &PipelineQueue{}, &PipelineQueueList{})
+		return nil
+	})
+}
@@ -10,6 +10,7 @@ package v1beta1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -33,6 +34,14 @@ type ProfileSpec struct {
 	// +kubebuilder:validation:MaxItems=20
 	// +listType=set
 	Artifacts []string `json:"artifacts" yaml:"artifacts" description:"A list of paths to the artifacts that will be produced by the scanners. These paths are relative to the results directory."`
+	// ArtifactChecks is a list of [ArtifactCheck] run by the sidecar against the artifacts once
+	// all scanners have completed, before any uploader is started. Each check must reference
+	// a path in [ProfileSpec.Artifacts]. Every artifact is always checked to exist.
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=map
+	// +listMapKey=path
+	ArtifactChecks []ArtifactCheck `json:"artifactChecks,omitempty" yaml:"artifactChecks,omitempty" description:"A list of checks run against the artifacts before they are uploaded."`
 	// Volumes is a list of [v1.Volume] that will be defined in the pod spec
 	// for the scanners. This is useful for sharing data between scanners
 	// +optional
@@ -68,8 +77,72 @@ type ProfileSpec struct {
 	// +listType=map
 	// +listMapKey=name
 	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,15,rep,name=imagePullSecrets"`
+
+	// FailurePolicy determines if the uploaders are run, and the phase the pipeline ends in,
+	// when one or more scanners exit with a non-zero exit code. Defaults to [ScannerFailurePolicyUploadThenFail].
+	// +optional
+	// +kubebuilder:default=UploadThenFail
+	FailurePolicy ScannerFailurePolicy `json:"failurePolicy,omitempty" description:"Determines if the uploaders are run when one or more scanners fail."`
+}
+
+// ArtifactContentType is the expected format of the contents of an artifact.
+// Artifacts that are [ArtifactContentTypeSARIF] or [ArtifactContentTypeCycloneDX]
+// are also summarized into [PipelineStatus.Findings].
+// +enum
+// +kubebuilder:validation:Enum=JSON;SARIF;CycloneDX
+type ArtifactContentType string
+
+const (
+	// ArtifactContentTypeJSON requires the artifact to be a valid JSON document.
+	ArtifactContentTypeJSON ArtifactContentType = "JSON"
+	// ArtifactContentTypeSARIF requires the artifact to be a SARIF log in JSON format,
+	// with a version and a list of runs.
+	ArtifactContentTypeSARIF ArtifactContentType = "SARIF"
+	// ArtifactContentTypeCycloneDX requires the artifact to be a CycloneDX BOM in JSON format,
+	// with the bomFormat 'CycloneDX' and a specVersion.
+	ArtifactContentTypeCycloneDX ArtifactContentType = "CycloneDX"
+)
+
+// ArtifactCheck is a check run against an artifact produced by the scanners.
+// An artifact that fails its check is not passed to the uploaders, and the
+// failure is reported in [PipelineStatus.Artifacts].
+type ArtifactCheck struct {
+	// Path is the path of the artifact to check, which must match
+	// one of the paths in [ProfileSpec.Artifacts].
+	// +required
+	Path string `json:"path" yaml:"path" description:"The path of the artifact to check, matching one of the artifacts of the profile."`
+
+	// MaxSize is the maximum size of the artifact. An empty value means no limit.
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty" yaml:"maxSize,omitempty" description:"The maximum size of the artifact."`
+
+	// ContentType is the expected format of the contents of the artifact.
+	// An empty value means the contents are not checked. SARIF and CycloneDX
+	// artifacts have their findings summarized in the status of the pipeline.
+	// +optional
+	ContentType ArtifactContentType `json:"contentType,omitempty" yaml:"contentType,omitempty" description:"The expected format of the artifact. One of JSON, SARIF or CycloneDX."`
 }
 
+// ScannerFailurePolicy determines how a pipeline handles scanners
+// that exit with a non-zero exit code.
+// +enum
+// +kubebuilder:validation:Enum=UploadThenFail;AllMustSucceed;UploadOnPartialFailure;IgnoreScannerFailures
+type ScannerFailurePolicy string
+
+const (
+	// ScannerFailurePolicyUploadThenFail always runs the uploaders, but fails the pipeline if any scanner fails.
+	// This allows the results of scanners that exit with a non-zero exit code when they find issues to be uploaded.
+	ScannerFailurePolicyUploadThenFail ScannerFailurePolicy = "UploadThenFail"
+	// ScannerFailurePolicyAllMustSucceed skips the uploaders and fails the pipeline if any scanner fails.
+	ScannerFailurePolicyAllMustSucceed ScannerFailurePolicy = "AllMustSucceed"
+	// ScannerFailurePolicyUploadOnPartialFailure runs the uploaders as long as at least one scanner succeeded.
+	// If any scanner failed, the pipeline ends in the [PipelinePartiallySucceeded] phase once the uploaders succeed.
+	ScannerFailurePolicyUploadOnPartialFailure ScannerFailurePolicy = "UploadOnPartialFailure"
+	// ScannerFailurePolicyIgnoreScannerFailures always runs the uploaders, and the pipeline ends in the
+	// [PipelineSucceeded] phase if the downloader and uploaders succeed, regardless of the scanners.
+	ScannerFailurePolicyIgnoreScannerFailures ScannerFailurePolicy = "IgnoreScannerFailures"
+)
+
 // ConditionalContainer represents a container that
 // is only included if a condition is met.
 type ConditionalContainer struct {
@@ -82,6 +155,14 @@ type ConditionalContainer struct {
 	// condition, the pipeline will fail to be created.
 	// +optional
 	IncludeIf *ContainerCondition `json:"includeIf,omitempty,omitzero" description:"Give conditions for when the container should be included. Null conditions means always include"`
+
+	// ActiveDeadlineSeconds is the duration in seconds the container may run for
+	// before it is signalled to stop by the sidecar. A scanner that exceeds its
+	// deadline is treated as failed, with the reason StageTimedOut.
+	// An empty value means no deadline.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty" description:"The duration in seconds the container may run for before it is stopped. Null means no deadline"`
 }
 
 type ProfileStatus struct {
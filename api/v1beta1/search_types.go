@@ -25,6 +25,10 @@ const (
 	// encoded pipeline template for the search scheduler.
 	PipelineTemplateAnnotation = Group + "/pipelineTemplate.json"
 
+	// PipelineTemplateLabelKey is the label key used to identify the named
+	// pipeline template of the search scheduler a pipeline was created from.
+	PipelineTemplateLabelKey = Group + "/pipelineTemplate"
+
 	// TTLSecondsAnnotation is the annotation containing the
 	// ttl in seconds for how long the search pod should live
 	// after completion
@@ -41,6 +45,11 @@ const (
 	// is used to query for child resources and hold off on completion until
 	// the child resources are complete as well.
 	ScheduledByLabelKey = Group + "/scheduledBy"
+
+	// CountedBySearchAnnotation is set on a Pipeline or Search scheduled by a Search
+	// once its outcome has been added to the [SearchChildrenStatus] of that Search,
+	// with the name of the Search as the value.
+	CountedBySearchAnnotation = Group + "/countedBySearch"
 )
 
 // SearchSpec defines the desired state of Search
@@ -79,6 +88,57 @@ type SearchSpec struct {
 	// entire pod, allowing resource sharing among containers in a pod.
 	// +optional
 	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// CrawlerStateClaimName is the name of a PersistentVolumeClaim in the same namespace
+	// to mount into the crawler container at the path in [EnvVarCrawlerStateDir], allowing
+	// the crawler to persist state between searches. Searches created by a CronSearch with
+	// state configured use the claim managed by the CronSearch.
+	// +optional
+	CrawlerStateClaimName string `json:"crawlerStateClaimName,omitempty"`
+
+	// SuccessPolicy determines if the search is marked as completed successfully, based
+	// on the outcome of the pipelines and searches it scheduled. A search whose crawler
+	// fails is always marked as failed. If omitted, only the crawler has to succeed.
+	// +optional
+	SuccessPolicy *SearchSuccessPolicy `json:"successPolicy,omitempty"`
+}
+
+// SearchSuccessPolicyType describes which outcomes of the children
+// of a search are required for the search to succeed.
+// +enum
+// +kubebuilder:validation:Enum=CrawlerSucceeded;AllChildrenSucceeded;FailureThreshold
+type SearchSuccessPolicyType string
+
+const (
+	// CrawlerSucceededPolicy marks the search as succeeded once the crawler
+	// succeeds and all children complete, regardless of their outcome.
+	CrawlerSucceededPolicy SearchSuccessPolicyType = "CrawlerSucceeded"
+	// AllChildrenSucceededPolicy marks the search as failed if any child failed,
+	// or the scheduler failed to create any child.
+	AllChildrenSucceededPolicy SearchSuccessPolicyType = "AllChildrenSucceeded"
+	// FailureThresholdPolicy marks the search as failed if the percentage of children that failed,
+	// or the scheduler failed to create, exceeds [SearchSuccessPolicy.MaxFailedPercent].
+	FailureThresholdPolicy SearchSuccessPolicyType = "FailureThreshold"
+)
+
+// SearchSuccessPolicy configures how the outcome of the pipelines and searches
+// scheduled by a search, as counted in [SearchStatus], determine its outcome.
+// Children scheduled by a search are not deleted by their TTL until the search
+// has counted them, so their outcome is seen by the policy.
+// Searches created by the scheduler use the same policy.
+type SearchSuccessPolicy struct {
+	// Policy is the success policy of the search.
+	// Defaults to "CrawlerSucceeded".
+	// +optional
+	// +kubebuilder:default=CrawlerSucceeded
+	Policy SearchSuccessPolicyType `json:"policy,omitempty"`
+
+	// MaxFailedPercent is the highest percentage of children that can fail for the search
+	// to succeed, when the policy is "FailureThreshold". Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxFailedPercent *int32 `json:"maxFailedPercent,omitempty"`
 }
 
 // SearchSchedulerSpec configures the scheduler sidecar container
@@ -94,11 +154,166 @@ type SearchSchedulerSpec struct {
 	// +optional
 	PipelineTemplate PipelineTemplate `json:"pipelineTemplate,omitzero"`
 
+	// PipelineTemplates are named pipeline templates that crawlers can select by name
+	// for each target, creating a pipeline from each selected template. This allows
+	// the same target to be processed by multiple profiles, without crawling twice.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	PipelineTemplates []NamedPipelineTemplate `json:"pipelineTemplates,omitempty"`
+
+	// DefaultPipelineTemplates are the names of the templates in [PipelineTemplates]
+	// used for targets that do not select any. If omitted, [PipelineTemplate] is used.
+	// +optional
+	// +listType=set
+	DefaultPipelineTemplates []string `json:"defaultPipelineTemplates,omitempty"`
+
 	// IntervalSeconds represents the amount of time to wait
 	// between creating pipelines. If not set, scheduler defaults to
 	// 60 (1 minute).
 	// +optional
 	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+
+	// MaxActivePipelines is the maximum number of pipelines created by
+	// this search that can be active at once. When set, the scheduler
+	// will wait for the number of active pipelines to drop below the limit
+	// before creating a new pipeline, instead of waiting [IntervalSeconds]
+	// after each creation.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxActivePipelines *int32 `json:"maxActivePipelines,omitempty"`
+
+	// MaxActiveSearches is the maximum number of searches created by
+	// this search that can be active at once. When set, the scheduler
+	// will wait for the number of active searches to drop below the limit
+	// before creating a new search, instead of waiting [IntervalSeconds]
+	// after each creation.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxActiveSearches *int32 `json:"maxActiveSearches,omitempty"`
+
+	// Deduplication configures whether the scheduler should skip creating
+	// a pipeline for a target that has already been processed by an
+	// equivalent pipeline. If omitted, a pipeline is created for every target.
+	// +optional
+	Deduplication *PipelineDeduplicationSpec `json:"deduplication,omitempty"`
+
+	// Overrides is the allow-list of fields of the pipeline template a crawler can
+	// replace for the pipeline of a single target. Targets sent with any override
+	// not allowed are rejected by the scheduler. If omitted, no overrides are allowed.
+	// +optional
+	Overrides *PipelineOverridePolicy `json:"overrides,omitempty"`
+}
+
+// PipelineDeduplicationPolicy describes when the scheduler should
+// skip creating a pipeline for a target.
+// +enum
+// +kubebuilder:validation:Enum=None;SkipIfSucceeded;SkipIfActiveOrSucceeded
+type PipelineDeduplicationPolicy string
+
+const (
+	// NoDeduplication creates a pipeline for every target received,
+	// regardless of any existing pipelines.
+	NoDeduplication PipelineDeduplicationPolicy = "None"
+	// SkipIfSucceeded skips creating a pipeline when an equivalent pipeline
+	// has already succeeded.
+	SkipIfSucceeded PipelineDeduplicationPolicy = "SkipIfSucceeded"
+	// SkipIfActiveOrSucceeded skips creating a pipeline when an equivalent
+	// pipeline has already succeeded or is still running.
+	SkipIfActiveOrSucceeded PipelineDeduplicationPolicy = "SkipIfActiveOrSucceeded"
+)
+
+// PipelineDeduplicationSpec configures how the scheduler determines if a
+// target has already been processed. Two pipelines are considered equivalent
+// when they have the same [TargetHashLabelKey] label, which is derived from
+// the target, profile reference and downloader reference of the pipeline.
+type PipelineDeduplicationSpec struct {
+	// Policy is the deduplication policy to apply to each target.
+	// Defaults to "None".
+	// +optional
+	// +kubebuilder:default=None
+	Policy PipelineDeduplicationPolicy `json:"policy,omitempty"`
+
+	// WindowSeconds limits the existing pipelines considered to those
+	// that completed (or for active pipelines, were created) within
+	// the given number of seconds. If not set, all existing pipelines are considered.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	WindowSeconds *int32 `json:"windowSeconds,omitempty"`
+}
+
+// NamedPipelineTemplate is a pipeline template that crawlers can select by name.
+type NamedPipelineTemplate struct {
+	// Name is the name crawlers select the template by. It is set as the
+	// [PipelineTemplateLabelKey] label of pipelines created from the template.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	PipelineTemplate `json:",inline"`
+}
+
+// PipelineOverridePolicy lists the fields of the pipeline template
+// that crawlers are allowed to override for a single target.
+type PipelineOverridePolicy struct {
+	// Profiles are the profiles a crawler can use in place of the profile of the pipeline template.
+	// +optional
+	// +listType=atomic
+	Profiles []AllowedReference `json:"profiles,omitempty"`
+
+	// Downloaders are the downloaders a crawler can use in place of the downloader of the pipeline template.
+	// +optional
+	// +listType=atomic
+	Downloaders []AllowedReference `json:"downloaders,omitempty"`
+
+	// ProfileParameters are the names of the parameters a crawler can set on the profile reference.
+	// +optional
+	// +listType=set
+	ProfileParameters []string `json:"profileParameters,omitempty"`
+
+	// DownloaderParameters are the names of the parameters a crawler can set on the downloader reference.
+	// +optional
+	// +listType=set
+	DownloaderParameters []string `json:"downloaderParameters,omitempty"`
+
+	// Labels are the keys of the labels a crawler can set on the pipeline.
+	// Keys with the prefix of ocular labels are not allowed.
+	// +optional
+	// +listType=set
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations are the keys of the annotations a crawler can set on the pipeline.
+	// Keys with the prefix of ocular annotations are not allowed.
+	// +optional
+	// +listType=set
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// AllowedReference is a resource that can be referenced in place of a reference of the pipeline template.
+type AllowedReference struct {
+	// Name is the name of the resource.
+	// +required
+	Name string `json:"name"`
+
+	// Kind is the kind of the resource. If omitted, the kind
+	// defaults to the namespaced kind, i.e. "Profile" or "Downloader".
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// Allows reports whether the reference is to the allowed resource,
+// where an empty kind of either is defaultKind.
+func (a AllowedReference) Allows(ref ParameterizedLocalObjectReference, defaultKind string) bool {
+	kind, allowedKind := ref.Kind, a.Kind
+	if kind == "" {
+		kind = defaultKind
+	}
+	if allowedKind == "" {
+		allowedKind = defaultKind
+	}
+	return a.Name == ref.Name && allowedKind == kind
 }
 
 // PipelineTemplate is the template for pipelines
@@ -140,11 +355,60 @@ type SearchStatus struct {
 	// CronSearchControllerName is the name of the controller that created this search.
 	// +optional
 	CronSearchControllerName *string `json:"cronSearchControllerName,omitempty" description:"The name of the controller that created this search."`
+
+	// Pipelines are the counts of the pipelines scheduled by the search.
+	// +optional
+	Pipelines SearchChildrenStatus `json:"pipelines,omitzero" description:"The counts of the pipelines scheduled by the search."`
+
+	// Searches are the counts of the searches scheduled by the search.
+	// +optional
+	Searches SearchChildrenStatus `json:"searches,omitzero" description:"The counts of the searches scheduled by the search."`
+}
+
+// SearchChildrenStatus are the counts of the pipelines or searches scheduled by a search,
+// labeled with [ScheduledByLabelKey]. The outcome of each child is recorded as it completes
+// and marked with [CountedBySearchAnnotation], so the counts are kept once the child is
+// deleted, such as by its own TTL, and never decrease.
+type SearchChildrenStatus struct {
+	// Scheduled is the number of children created by the scheduler, as reported by the
+	// scheduler once the crawler has exited. Until then, it is the number of children seen.
+	// +optional
+	Scheduled int32 `json:"scheduled,omitempty"`
+
+	// Running is the number of children that have not completed.
+	// +optional
+	Running int32 `json:"running,omitempty"`
+
+	// Succeeded is the number of children that completed successfully.
+	// A pipeline in the [PipelinePartiallySucceeded] phase is counted as succeeded.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of children that completed unsuccessfully.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// FailedToSchedule is the number of children the scheduler was unable to create,
+	// either because the request of the crawler was rejected or the creation failed.
+	// Targets skipped by deduplication are not counted. This is reported by the scheduler
+	// once the crawler has exited.
+	// +optional
+	FailedToSchedule int32 `json:"failedToSchedule,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:selectablefield:JSONPath=`.status.cronSearchControllerName`
+// +kubebuilder:printcolumn:name="Crawler",type=string,JSONPath=`.spec.crawlerRef.name`
+// +kubebuilder:printcolumn:name="Pipelines",type=integer,JSONPath=`.status.pipelines.scheduled`
+// +kubebuilder:printcolumn:name="Running",type=integer,JSONPath=`.status.pipelines.running`
+// +kubebuilder:printcolumn:name="Succeeded",type=integer,JSONPath=`.status.pipelines.succeeded`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.pipelines.failed`
+// +kubebuilder:printcolumn:name="Unscheduled",type=integer,JSONPath=`.status.pipelines.failedToSchedule`
+// +kubebuilder:printcolumn:name="Searches",type=integer,JSONPath=`.status.searches.scheduled`
+// +kubebuilder:printcolumn:name="Searches Failed",type=integer,JSONPath=`.status.searches.failed`,priority=1
+// +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completionTime`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +genclient
 
 // Search is the Schema for the searches API
@@ -18,7 +18,15 @@ import (
 type UploaderSpec struct {
 	// Container is the container that will be run to download the target.
 	// It must be a valid [v1.Container] that can be run in a Kubernetes pod.
-	Container v1.Container `json:"container" protobuf:"bytes,1,opt,name=container"`
+	// Exactly one of Container or S3 must be set.
+	// +optional
+	Container v1.Container `json:"container,omitzero" protobuf:"bytes,1,opt,name=container"`
+
+	// S3 configures the uploader to upload the artifacts and metadata files of the pipeline
+	// to an S3 compatible object store, using the ocular sidecar in place of a container.
+	// Exactly one of Container or S3 must be set.
+	// +optional
+	S3 *S3UploaderSpec `json:"s3,omitempty" protobuf:"bytes,5,opt,name=s3"`
 
 	// List of volumes that can be mounted by containers belonging to the pod.
 	// This list of volumes will be appended to the [k8s.io/api/core/v1.PodSpec] that runs the uploader,
@@ -50,6 +58,56 @@ type UploaderSpec struct {
 	// +listType=map
 	// +listMapKey=name
 	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,15,rep,name=imagePullSecrets"`
+
+	// ActiveDeadlineSeconds is the duration in seconds the uploader may run for,
+	// starting once all scanners have completed, before it is signalled to stop and
+	// the [Pipeline] fails with the reason StageTimedOut. Value must be a positive integer.
+	// An empty value means no deadline.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty" protobuf:"varint,4,opt,name=activeDeadlineSeconds"`
+}
+
+// S3UploaderDefaultKeyTemplate is the [S3UploaderSpec.KeyTemplate] used when none is set.
+const S3UploaderDefaultKeyTemplate = "{namespace}/{profile}/{target}/{version}/{pipeline}"
+
+// S3UploaderSpec configures an uploader that uploads each artifact and metadata file
+// of a pipeline as an object to an S3 compatible object store, such as AWS S3 or MinIO.
+// The key of each object is the expanded [S3UploaderSpec.KeyTemplate] followed by the path
+// of the file relative to the pipeline volumes, e.g. results/report.json or metadata/commit.json.
+type S3UploaderSpec struct {
+	// Bucket is the name of the bucket to upload the objects to.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket" protobuf:"bytes,1,opt,name=bucket"`
+
+	// Endpoint is the URL of the S3 compatible API, e.g. http://minio.minio.svc:9000.
+	// An empty value means the AWS S3 endpoint for the region.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty" protobuf:"bytes,2,opt,name=endpoint"`
+
+	// Region is the region of the bucket, used to sign requests.
+	// An empty value means us-east-1.
+	// +optional
+	Region string `json:"region,omitempty" protobuf:"bytes,3,opt,name=region"`
+
+	// ForcePathStyle addresses the bucket in the path of the URL (endpoint/bucket/key) instead
+	// of the host (bucket.endpoint/key). Most S3 compatible object stores, such as MinIO, require this.
+	// +optional
+	ForcePathStyle bool `json:"forcePathStyle,omitempty" protobuf:"varint,4,opt,name=forcePathStyle"`
+
+	// KeyTemplate is the prefix of the key of each object, where each of {namespace}, {profile},
+	// {downloader}, {uploader}, {pipeline}, {target} and {version} is replaced with the value for
+	// the pipeline. Empty segments are removed, so a target without a version has no version segment.
+	// An empty value means [S3UploaderDefaultKeyTemplate].
+	// +optional
+	KeyTemplate string `json:"keyTemplate,omitempty" protobuf:"bytes,5,opt,name=keyTemplate"`
+
+	// CredentialsSecretRef is a reference to a secret, in the namespace of the pipeline, with the
+	// credentials to upload with. The secret must have the keys AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY,
+	// and can optionally have the key AWS_SESSION_TOKEN.
+	// +required
+	CredentialsSecretRef v1.LocalObjectReference `json:"credentialsSecretRef" protobuf:"bytes,6,opt,name=credentialsSecretRef"`
 }
 
 type UploaderStatus struct {
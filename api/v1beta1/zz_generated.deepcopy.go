@@ -18,6 +18,70 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedReference) DeepCopyInto(out *AllowedReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedReference.
+func (in *AllowedReference) DeepCopy() *AllowedReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactCheck) DeepCopyInto(out *ArtifactCheck) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactCheck.
+func (in *ArtifactCheck) DeepCopy() *ArtifactCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactFindings) DeepCopyInto(out *ArtifactFindings) {
+	*out = *in
+	if in.BySeverity != nil {
+		in, out := &in.BySeverity, &out.BySeverity
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ByRule != nil {
+		in, out := &in.ByRule, &out.ByRule
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactFindings.
+func (in *ArtifactFindings) DeepCopy() *ArtifactFindings {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactFindings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterCrawler) DeepCopyInto(out *ClusterCrawler) {
 	*out = *in
@@ -180,6 +244,87 @@ func (in *ClusterDownloaderStatus) DeepCopy() *ClusterDownloaderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPipelineQueue) DeepCopyInto(out *ClusterPipelineQueue) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPipelineQueue.
+func (in *ClusterPipelineQueue) DeepCopy() *ClusterPipelineQueue {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPipelineQueue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPipelineQueue) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPipelineQueueList) DeepCopyInto(out *ClusterPipelineQueueList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterPipelineQueue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPipelineQueueList.
+func (in *ClusterPipelineQueueList) DeepCopy() *ClusterPipelineQueueList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPipelineQueueList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPipelineQueueList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPipelineQueueStatus) DeepCopyInto(out *ClusterPipelineQueueStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPipelineQueueStatus.
+func (in *ClusterPipelineQueueStatus) DeepCopy() *ClusterPipelineQueueStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPipelineQueueStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterUploader) DeepCopyInto(out *ClusterUploader) {
 	*out = *in
@@ -270,6 +415,11 @@ func (in *ConditionalContainer) DeepCopyInto(out *ConditionalContainer) {
 		*out = new(ContainerCondition)
 		**out = **in
 	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionalContainer.
@@ -391,6 +541,36 @@ func (in *CrawlerSpec) DeepCopy() *CrawlerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrawlerStateSpec) DeepCopyInto(out *CrawlerStateSpec) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrawlerStateSpec.
+func (in *CrawlerStateSpec) DeepCopy() *CrawlerStateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrawlerStateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrawlerStatus) DeepCopyInto(out *CrawlerStatus) {
 	*out = *in
@@ -496,6 +676,11 @@ func (in *CronSearchSpec) DeepCopyInto(out *CronSearchSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.CrawlerState != nil {
+		in, out := &in.CrawlerState, &out.CrawlerState
+		*out = new(CrawlerStateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronSearchSpec.
@@ -626,6 +811,11 @@ func (in *DownloaderSpec) DeepCopyInto(out *DownloaderSpec) {
 		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownloaderSpec.
@@ -660,6 +850,22 @@ func (in *DownloaderStatus) DeepCopy() *DownloaderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamedPipelineTemplate) DeepCopyInto(out *NamedPipelineTemplate) {
+	*out = *in
+	in.PipelineTemplate.DeepCopyInto(&out.PipelineTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedPipelineTemplate.
+func (in *NamedPipelineTemplate) DeepCopy() *NamedPipelineTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedPipelineTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ParameterDefinition) DeepCopyInto(out *ParameterDefinition) {
 	*out = *in
@@ -764,6 +970,96 @@ func (in *Pipeline) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineArtifactStatus) DeepCopyInto(out *PipelineArtifactStatus) {
+	*out = *in
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = new(ArtifactFindings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineArtifactStatus.
+func (in *PipelineArtifactStatus) DeepCopy() *PipelineArtifactStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineArtifactStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineContainerStatus) DeepCopyInto(out *PipelineContainerStatus) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineContainerStatus.
+func (in *PipelineContainerStatus) DeepCopy() *PipelineContainerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineContainerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineDeduplicationSpec) DeepCopyInto(out *PipelineDeduplicationSpec) {
+	*out = *in
+	if in.WindowSeconds != nil {
+		in, out := &in.WindowSeconds, &out.WindowSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineDeduplicationSpec.
+func (in *PipelineDeduplicationSpec) DeepCopy() *PipelineDeduplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineDeduplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineFindings) DeepCopyInto(out *PipelineFindings) {
+	*out = *in
+	if in.BySeverity != nil {
+		in, out := &in.BySeverity, &out.BySeverity
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineFindings.
+func (in *PipelineFindings) DeepCopy() *PipelineFindings {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineFindings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineList) DeepCopyInto(out *PipelineList) {
 	*out = *in
@@ -796,6 +1092,192 @@ func (in *PipelineList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineOverridePolicy) DeepCopyInto(out *PipelineOverridePolicy) {
+	*out = *in
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]AllowedReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Downloaders != nil {
+		in, out := &in.Downloaders, &out.Downloaders
+		*out = make([]AllowedReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProfileParameters != nil {
+		in, out := &in.ProfileParameters, &out.ProfileParameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DownloaderParameters != nil {
+		in, out := &in.DownloaderParameters, &out.DownloaderParameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineOverridePolicy.
+func (in *PipelineOverridePolicy) DeepCopy() *PipelineOverridePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineOverridePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineQueue) DeepCopyInto(out *PipelineQueue) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineQueue.
+func (in *PipelineQueue) DeepCopy() *PipelineQueue {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineQueue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelineQueue) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineQueueList) DeepCopyInto(out *PipelineQueueList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PipelineQueue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineQueueList.
+func (in *PipelineQueueList) DeepCopy() *PipelineQueueList {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineQueueList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PipelineQueueList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineQueueSpec) DeepCopyInto(out *PipelineQueueSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Downloaders != nil {
+		in, out := &in.Downloaders, &out.Downloaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineQueueSpec.
+func (in *PipelineQueueSpec) DeepCopy() *PipelineQueueSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineQueueSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineQueueStatus) DeepCopyInto(out *PipelineQueueStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineQueueStatus.
+func (in *PipelineQueueStatus) DeepCopy() *PipelineQueueStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineQueueStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineRetryPolicy) DeepCopyInto(out *PipelineRetryPolicy) {
+	*out = *in
+	if in.BackoffSeconds != nil {
+		in, out := &in.BackoffSeconds, &out.BackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBackoffSeconds != nil {
+		in, out := &in.MaxBackoffSeconds, &out.MaxBackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]PipelineStage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRetryPolicy.
+func (in *PipelineRetryPolicy) DeepCopy() *PipelineRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 	*out = *in
@@ -829,6 +1311,21 @@ func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(PipelineRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(PipelineStorage)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpec.
@@ -875,6 +1372,25 @@ func (in *PipelineStatus) DeepCopyInto(out *PipelineStatus) {
 		*out = (*in).DeepCopy()
 	}
 	out.StageStatuses = in.StageStatuses
+	if in.ContainerStatuses != nil {
+		in, out := &in.ContainerStatuses, &out.ContainerStatuses
+		*out = make([]PipelineContainerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]PipelineArtifactStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = new(PipelineFindings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStatus.
@@ -887,6 +1403,36 @@ func (in *PipelineStatus) DeepCopy() *PipelineStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineStorage) DeepCopyInto(out *PipelineStorage) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStorage.
+func (in *PipelineStorage) DeepCopy() *PipelineStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineTemplate) DeepCopyInto(out *PipelineTemplate) {
 	*out = *in
@@ -978,6 +1524,13 @@ func (in *ProfileSpec) DeepCopyInto(out *ProfileSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ArtifactChecks != nil {
+		in, out := &in.ArtifactChecks, &out.ArtifactChecks
+		*out = make([]ArtifactCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]corev1.Volume, len(*in))
@@ -1038,6 +1591,22 @@ func (in *ProfileStatus) DeepCopy() *ProfileStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3UploaderSpec) DeepCopyInto(out *S3UploaderSpec) {
+	*out = *in
+	out.CredentialsSecretRef = in.CredentialsSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3UploaderSpec.
+func (in *S3UploaderSpec) DeepCopy() *S3UploaderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(S3UploaderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Search) DeepCopyInto(out *Search) {
 	*out = *in
@@ -1065,6 +1634,21 @@ func (in *Search) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SearchChildrenStatus) DeepCopyInto(out *SearchChildrenStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchChildrenStatus.
+func (in *SearchChildrenStatus) DeepCopy() *SearchChildrenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SearchChildrenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchList) DeepCopyInto(out *SearchList) {
 	*out = *in
@@ -1101,11 +1685,43 @@ func (in *SearchList) DeepCopyObject() runtime.Object {
 func (in *SearchSchedulerSpec) DeepCopyInto(out *SearchSchedulerSpec) {
 	*out = *in
 	in.PipelineTemplate.DeepCopyInto(&out.PipelineTemplate)
+	if in.PipelineTemplates != nil {
+		in, out := &in.PipelineTemplates, &out.PipelineTemplates
+		*out = make([]NamedPipelineTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultPipelineTemplates != nil {
+		in, out := &in.DefaultPipelineTemplates, &out.DefaultPipelineTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.IntervalSeconds != nil {
 		in, out := &in.IntervalSeconds, &out.IntervalSeconds
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxActivePipelines != nil {
+		in, out := &in.MaxActivePipelines, &out.MaxActivePipelines
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxActiveSearches != nil {
+		in, out := &in.MaxActiveSearches, &out.MaxActiveSearches
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Deduplication != nil {
+		in, out := &in.Deduplication, &out.Deduplication
+		*out = new(PipelineDeduplicationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(PipelineOverridePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchSchedulerSpec.
@@ -1138,6 +1754,11 @@ func (in *SearchSpec) DeepCopyInto(out *SearchSpec) {
 		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SuccessPolicy != nil {
+		in, out := &in.SuccessPolicy, &out.SuccessPolicy
+		*out = new(SearchSuccessPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchSpec.
@@ -1173,6 +1794,8 @@ func (in *SearchStatus) DeepCopyInto(out *SearchStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	out.Pipelines = in.Pipelines
+	out.Searches = in.Searches
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchStatus.
@@ -1185,6 +1808,26 @@ func (in *SearchStatus) DeepCopy() *SearchStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SearchSuccessPolicy) DeepCopyInto(out *SearchSuccessPolicy) {
+	*out = *in
+	if in.MaxFailedPercent != nil {
+		in, out := &in.MaxFailedPercent, &out.MaxFailedPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchSuccessPolicy.
+func (in *SearchSuccessPolicy) DeepCopy() *SearchSuccessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SearchSuccessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchTemplateSpec) DeepCopyInto(out *SearchTemplateSpec) {
 	*out = *in
@@ -1296,6 +1939,11 @@ func (in *UploaderList) DeepCopyObject() runtime.Object {
 func (in *UploaderSpec) DeepCopyInto(out *UploaderSpec) {
 	*out = *in
 	in.Container.DeepCopyInto(&out.Container)
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3UploaderSpec)
+		**out = **in
+	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]corev1.Volume, len(*in))
@@ -1315,6 +1963,11 @@ func (in *UploaderSpec) DeepCopyInto(out *UploaderSpec) {
 		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UploaderSpec.
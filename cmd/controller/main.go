@@ -33,6 +33,7 @@ import (
 
 	ocularcrashoverriderunv1beta1 "github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/crashappsec/ocular/internal/controller"
+	"github.com/crashappsec/ocular/internal/ingest"
 	webhookv1beta1 "github.com/crashappsec/ocular/internal/webhook/v1beta1"
 	// +kubebuilder:scaffold:imports
 )
@@ -60,6 +61,7 @@ func main() {
 	var webhookCertPath, webhookCertName, webhookCertKey string
 	var enableLeaderElection bool
 	var probeAddr string
+	var ingestAddr, ingestConfigPath string
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
@@ -78,6 +80,9 @@ func main() {
 		"The directory that contains the metrics server certificate.")
 	flag.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
+	flag.StringVar(&ingestAddr, "ingest-bind-address", "0", "The address the ingestion receiver binds to, "+
+		"which creates pipelines from signed push events or targets. Leave as 0 to disable the receiver.")
+	flag.StringVar(&ingestConfigPath, "ingest-config", "", "The path of the configuration file of the ingestion receiver.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	opts := zap.Options{}
@@ -282,6 +287,22 @@ func main() {
 	}
 	// +kubebuilder:scaffold:builder
 
+	if ingestAddr != "0" {
+		ingestConfig, err := ingest.LoadConfig(ingestConfigPath)
+		if err != nil {
+			setupLog.Error(err, "unable to load ingest config", "ingest-config", ingestConfigPath)
+			os.Exit(1)
+		}
+		if err := mgr.Add(&ingest.Server{
+			Addr:   ingestAddr,
+			Client: mgr.GetClient(),
+			Config: ingestConfig,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up ingest server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,183 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/generated/clientset"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// activeWatchTimeoutSeconds is the timeout for each watch
+// request, after which the active resources will be re-listed.
+// This guards against any missed events from the API server.
+const activeWatchTimeoutSeconds int64 = 60
+
+// activeLimiter blocks the scheduler until the number of active
+// resources scheduled by the search drops below a limit.
+type activeLimiter struct {
+	resource string
+	limit    int
+	// list returns the number of active resources
+	// and the resource version of the list.
+	list  func(ctx context.Context, opts metav1.ListOptions) (int, string, error)
+	watch func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	// selector is the label selector for resources scheduled by the search
+	selector string
+}
+
+// newPipelineLimiter returns an [activeLimiter] for pipelines scheduled by the search,
+// where a pipeline is considered active until it has a completion time.
+// If limit is not positive, nil is returned.
+func newPipelineLimiter(cs clientset.Interface, namespace, searchName string, limit int) *activeLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	pipelines := cs.ApiV1beta1().Pipelines(namespace)
+	return &activeLimiter{
+		resource: "pipelines",
+		limit:    limit,
+		selector: scheduledBySelector(searchName),
+		watch:    pipelines.Watch,
+		list: func(ctx context.Context, opts metav1.ListOptions) (int, string, error) {
+			pipelineList, err := pipelines.List(ctx, opts)
+			if err != nil {
+				return 0, "", err
+			}
+			var active int
+			for _, p := range pipelineList.Items {
+				if p.Status.CompletionTime == nil {
+					active++
+				}
+			}
+			return active, pipelineList.ResourceVersion, nil
+		},
+	}
+}
+
+// newSearchLimiter returns an [activeLimiter] for searches scheduled by the search,
+// where a search is considered active until it has a completion time.
+// If limit is not positive, nil is returned.
+func newSearchLimiter(cs clientset.Interface, namespace, searchName string, limit int) *activeLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	searches := cs.ApiV1beta1().Searches(namespace)
+	return &activeLimiter{
+		resource: "searches",
+		limit:    limit,
+		selector: scheduledBySelector(searchName),
+		watch:    searches.Watch,
+		list: func(ctx context.Context, opts metav1.ListOptions) (int, string, error) {
+			searchList, err := searches.List(ctx, opts)
+			if err != nil {
+				return 0, "", err
+			}
+			var active int
+			for _, s := range searchList.Items {
+				if s.Status.CompletionTime == nil {
+					active++
+				}
+			}
+			return active, searchList.ResourceVersion, nil
+		},
+	}
+}
+
+func scheduledBySelector(searchName string) string {
+	return labels.SelectorFromSet(labels.Set{
+		v1beta1.ScheduledByLabelKey: searchName,
+	}).String()
+}
+
+// Wait blocks until the number of active resources is below the limit
+// or the context is cancelled. When at the limit, it watches the resources
+// and re-counts the active resources on each change.
+func (l *activeLimiter) Wait(ctx context.Context) error {
+	for {
+		active, resourceVersion, err := l.list(ctx, metav1.ListOptions{LabelSelector: l.selector})
+		if err != nil {
+			return fmt.Errorf("unable to list active %s: %w", l.resource, err)
+		}
+		if active < l.limit {
+			return nil
+		}
+
+		slog.Info(fmt.Sprintf("active %s at limit, waiting for %s to complete", l.resource, l.resource),
+			slog.Int("active", active), slog.Int("limit", l.limit))
+		w, err := l.watch(ctx, metav1.ListOptions{
+			LabelSelector:   l.selector,
+			ResourceVersion: resourceVersion,
+			TimeoutSeconds:  new(activeWatchTimeoutSeconds),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to watch active %s: %w", l.resource, err)
+		}
+		err = awaitChange(ctx, w)
+		w.Stop()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// awaitChange waits for a resource to be modified or deleted,
+// or for the watch to close.
+func awaitChange(ctx context.Context, w watch.Interface) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch event.Type {
+			case watch.Modified, watch.Deleted:
+				return nil
+			case watch.Error:
+				slog.Warn("received error event while watching active resources", slog.Any("event", event.Object))
+				return nil
+			}
+		}
+	}
+}
+
+// parseLimitFromEnv parses a limit from the given environment variable.
+// An empty or invalid value will return 0, representing no limit.
+func parseLimitFromEnv(env v1beta1.EnvironmentVariableName) int {
+	value := os.Getenv(env)
+	if value == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		slog.Error("unable to parse limit, disabling limit", slog.String("env", env), slog.String("value", value), slog.Any("error", err))
+		return 0
+	}
+	return limit
+}
+
+// limitSpec converts a limit parsed by [parseLimitFromEnv]
+// back to the value for a [v1beta1.SearchSchedulerSpec].
+func limitSpec(limit int) *int32 {
+	if limit <= 0 {
+		return nil
+	}
+	return new(int32(limit))
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/generated/clientset/fake"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPipelineLimiterWait(t *testing.T) {
+	const (
+		namespace  = "default"
+		searchName = "my-search"
+	)
+	newPipeline := func(name, scheduledBy string, completed bool) *v1beta1.Pipeline {
+		p := &v1beta1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{v1beta1.ScheduledByLabelKey: scheduledBy},
+			},
+		}
+		if completed {
+			p.Status.CompletionTime = new(metav1.Now())
+		}
+		return p
+	}
+
+	if newPipelineLimiter(fake.NewSimpleClientset(), namespace, searchName, 0) != nil {
+		t.Fatalf("expected no limiter when limit is 0")
+	}
+
+	cs := fake.NewSimpleClientset(
+		newPipeline("active", searchName, false),
+		newPipeline("completed", searchName, true),
+		newPipeline("other-search", "other", false),
+	)
+	watcher := watch.NewFake()
+	cs.PrependWatchReactor("pipelines", k8stesting.DefaultWatchReactor(watcher, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// only a single pipeline is active for the search
+	if err := newPipelineLimiter(cs, namespace, searchName, 2).Wait(ctx); err != nil {
+		t.Fatalf("unexpected error waiting below limit: %v", err)
+	}
+
+	limiter := newPipelineLimiter(cs, namespace, searchName, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- limiter.Wait(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected wait to block while at limit, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// complete the active pipeline, and notify the watcher
+	completed := newPipeline("active", searchName, true)
+	if _, err := cs.ApiV1beta1().Pipelines(namespace).UpdateStatus(ctx, completed, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unable to update pipeline: %v", err)
+	}
+	watcher.Modify(completed)
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error waiting for pipeline to complete: %v", err)
+	}
+}
+
+func TestParseLimitFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{value: "", expected: 0},
+		{value: "5", expected: 5},
+		{value: "-1", expected: 0},
+		{value: "invalid", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(v1beta1.EnvVarPipelineSchedulerMaxActivePipelines, tt.value)
+			if got := parseLimitFromEnv(v1beta1.EnvVarPipelineSchedulerMaxActivePipelines); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/generated/clientset"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// deduplicator determines if a pipeline should be skipped
+// because an equivalent pipeline has already processed the target.
+type deduplicator struct {
+	cs        clientset.Interface
+	namespace string
+	policy    v1beta1.PipelineDeduplicationPolicy
+	// window is the duration of existing pipelines to consider,
+	// zero means all pipelines are considered.
+	window time.Duration
+}
+
+// parseDeduplicatorFromEnv parses the deduplication settings from
+// [v1beta1.EnvVarPipelineDeduplicationPolicy] and [v1beta1.EnvVarPipelineDeduplicationWindowSeconds].
+func parseDeduplicatorFromEnv(cs clientset.Interface, namespace string) deduplicator {
+	d := deduplicator{
+		cs:        cs,
+		namespace: namespace,
+		policy:    v1beta1.PipelineDeduplicationPolicy(os.Getenv(v1beta1.EnvVarPipelineDeduplicationPolicy)),
+	}
+	switch d.policy {
+	case v1beta1.SkipIfSucceeded, v1beta1.SkipIfActiveOrSucceeded, v1beta1.NoDeduplication:
+	case "":
+		d.policy = v1beta1.NoDeduplication
+	default:
+		slog.Error("unknown deduplication policy, disabling deduplication", slog.String("policy", string(d.policy)))
+		d.policy = v1beta1.NoDeduplication
+	}
+
+	if windowEnv := os.Getenv(v1beta1.EnvVarPipelineDeduplicationWindowSeconds); windowEnv != "" {
+		windowSeconds, err := strconv.Atoi(windowEnv)
+		if err != nil || windowSeconds < 0 {
+			slog.Error("unable to parse deduplication window, considering all pipelines", slog.String("window", windowEnv), slog.Any("error", err))
+		} else {
+			d.window = time.Duration(windowSeconds) * time.Second
+		}
+	}
+	return d
+}
+
+// ShouldSkip returns the name of an existing pipeline matching the given
+// [v1beta1.TargetHashLabelKey] value which satisfies the deduplication policy.
+// If no such pipeline exists, an empty string is returned.
+func (d deduplicator) ShouldSkip(ctx context.Context, targetHash string) (string, error) {
+	if d.policy == v1beta1.NoDeduplication {
+		return "", nil
+	}
+
+	pipelines, err := d.cs.ApiV1beta1().Pipelines(d.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			v1beta1.TargetHashLabelKey: targetHash,
+		}).String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to list pipelines for target hash %s: %w", targetHash, err)
+	}
+
+	now := time.Now()
+	for _, p := range pipelines.Items {
+		switch {
+		case p.Status.CompletionTime == nil && d.policy == v1beta1.SkipIfActiveOrSucceeded:
+			if d.withinWindow(now, p.CreationTimestamp) {
+				return p.Name, nil
+			}
+		case p.Status.CompletionTime != nil && p.Status.Phase == v1beta1.PipelineSucceeded:
+			if d.withinWindow(now, *p.Status.CompletionTime) {
+				return p.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (d deduplicator) withinWindow(now time.Time, t metav1.Time) bool {
+	return d.window == 0 || now.Sub(t.Time) <= d.window
+}
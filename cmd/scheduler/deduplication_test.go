@@ -0,0 +1,109 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/generated/clientset/fake"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeduplicatorShouldSkip(t *testing.T) {
+	const (
+		namespace = "default"
+		hash      = "target-hash"
+	)
+	now := time.Now()
+	newPipeline := func(name string, phase v1beta1.PipelinePhase, created time.Time, completed *time.Time) *v1beta1.Pipeline {
+		p := &v1beta1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				Labels:            map[string]string{v1beta1.TargetHashLabelKey: hash},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: v1beta1.PipelineStatus{Phase: phase},
+		}
+		if completed != nil {
+			p.Status.CompletionTime = new(metav1.NewTime(*completed))
+		}
+		return p
+	}
+
+	var (
+		active    = newPipeline("active", v1beta1.PipelineScanning, now.Add(-time.Minute), nil)
+		succeeded = newPipeline("succeeded", v1beta1.PipelineSucceeded, now.Add(-2*time.Hour), new(now.Add(-time.Hour)))
+		failed    = newPipeline("failed", v1beta1.PipelineFailed, now.Add(-2*time.Hour), new(now.Add(-time.Hour)))
+	)
+
+	tests := []struct {
+		name      string
+		policy    v1beta1.PipelineDeduplicationPolicy
+		window    time.Duration
+		pipelines []*v1beta1.Pipeline
+		expected  string
+	}{
+		{
+			name:      "no deduplication",
+			policy:    v1beta1.NoDeduplication,
+			pipelines: []*v1beta1.Pipeline{succeeded},
+			expected:  "",
+		},
+		{
+			name:      "skip if succeeded with succeeded pipeline",
+			policy:    v1beta1.SkipIfSucceeded,
+			pipelines: []*v1beta1.Pipeline{failed, succeeded},
+			expected:  succeeded.Name,
+		},
+		{
+			name:      "skip if succeeded ignores active and failed pipelines",
+			policy:    v1beta1.SkipIfSucceeded,
+			pipelines: []*v1beta1.Pipeline{active, failed},
+			expected:  "",
+		},
+		{
+			name:      "skip if succeeded outside of window",
+			policy:    v1beta1.SkipIfSucceeded,
+			window:    time.Minute * 30,
+			pipelines: []*v1beta1.Pipeline{succeeded},
+			expected:  "",
+		},
+		{
+			name:      "skip if active or succeeded with active pipeline",
+			policy:    v1beta1.SkipIfActiveOrSucceeded,
+			window:    time.Minute * 30,
+			pipelines: []*v1beta1.Pipeline{failed, active},
+			expected:  active.Name,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset()
+			for _, p := range tt.pipelines {
+				if _, err := cs.ApiV1beta1().Pipelines(namespace).Create(context.Background(), p, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("unable to create pipeline: %v", err)
+				}
+			}
+			d := deduplicator{cs: cs, namespace: namespace, policy: tt.policy, window: tt.window}
+			got, err := d.ShouldSkip(context.Background(), hash)
+			if err != nil {
+				t.Fatalf("ShouldSkip() returned error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("ShouldSkip() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
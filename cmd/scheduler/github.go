@@ -0,0 +1,354 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/process"
+	"github.com/crashappsec/ocular/pkg/runtime"
+)
+
+// Parameters of the GitHub organization crawler, read with [runtime.GetParameterFromEnvironment].
+const (
+	githubParamOrganization     = "GITHUB_ORGANIZATION"
+	githubParamAPIURL           = "GITHUB_API_URL"
+	githubParamIncludeArchived  = "INCLUDE_ARCHIVED"
+	githubParamIncludeForks     = "INCLUDE_FORKS"
+	githubParamTopics           = "TOPICS"
+	githubParamExcludeTopics    = "EXCLUDE_TOPICS"
+	githubParamLanguages        = "LANGUAGES"
+	githubParamExcludeLanguages = "EXCLUDE_LANGUAGES"
+
+	// githubTokenEnvVar is the environment variable containing the token used to authenticate
+	// to the GitHub API, which should be set from a Secret rather than a parameter.
+	githubTokenEnvVar = "GITHUB_TOKEN"
+
+	defaultGitHubAPIURL  = "https://api.github.com"
+	githubPageSize       = 100
+	githubRequestTimeout = 30 * time.Second
+
+	// githubMaxRateLimitRetries is the number of times a request is retried once rate limited.
+	githubMaxRateLimitRetries = 3
+	// githubMaxRateLimitWait is the longest the crawler waits for a rate limit to reset,
+	// failing the request instead of waiting longer.
+	githubMaxRateLimitWait = 15 * time.Minute
+	// githubSecondaryRateLimitWait is the wait for a secondary rate limit
+	// which does not specify when to retry, as recommended by GitHub.
+	githubSecondaryRateLimitWait = time.Minute
+)
+
+// githubCrawlerConfig configures which repositories of the
+// organization the GitHub crawler emits targets for.
+type githubCrawlerConfig struct {
+	apiURL          string
+	organization    string
+	token           string
+	includeArchived bool
+	includeForks    bool
+	// topics, if not empty, limits repositories to those with any of the topics.
+	topics        []string
+	excludeTopics []string
+	// languages, if not empty, limits repositories to those with any of the
+	// primary languages, compared case-insensitively.
+	languages        []string
+	excludeLanguages []string
+}
+
+// githubRepository is the subset of a repository returned by the GitHub API used by the crawler.
+type githubRepository struct {
+	FullName      string   `json:"full_name"`
+	HTMLURL       string   `json:"html_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Archived      bool     `json:"archived"`
+	Fork          bool     `json:"fork"`
+	Language      string   `json:"language"`
+	Topics        []string `json:"topics"`
+}
+
+// githubBranch is the subset of a branch returned by the GitHub API used by the crawler.
+type githubBranch struct {
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// githubStatusError is returned by [githubCrawler.get] for an unexpected response status.
+type githubStatusError struct {
+	StatusCode int
+	Status     string
+	URL        string
+	Body       string
+}
+
+func (e *githubStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s from %s: %s", e.Status, e.URL, e.Body)
+}
+
+// githubCrawlerConfigFromEnv parses the configuration of the crawler from its parameters.
+func githubCrawlerConfigFromEnv() (githubCrawlerConfig, error) {
+	param := func(name string) string {
+		value, _ := runtime.GetParameterFromEnvironment(name)
+		return strings.TrimSpace(value)
+	}
+	boolParam := func(name string) (bool, error) {
+		value := param(name)
+		if value == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid value '%s' for parameter %s: %w", value, name, err)
+		}
+		return b, nil
+	}
+
+	config := githubCrawlerConfig{
+		apiURL:           strings.TrimRight(param(githubParamAPIURL), "/"),
+		organization:     param(githubParamOrganization),
+		token:            os.Getenv(githubTokenEnvVar),
+		topics:           splitList(param(githubParamTopics)),
+		excludeTopics:    splitList(param(githubParamExcludeTopics)),
+		languages:        splitList(param(githubParamLanguages)),
+		excludeLanguages: splitList(param(githubParamExcludeLanguages)),
+	}
+	if config.apiURL == "" {
+		config.apiURL = defaultGitHubAPIURL
+	}
+	if config.organization == "" {
+		return config, fmt.Errorf("parameter %s is required", githubParamOrganization)
+	}
+	var errArchived, errForks error
+	config.includeArchived, errArchived = boolParam(githubParamIncludeArchived)
+	config.includeForks, errForks = boolParam(githubParamIncludeForks)
+	return config, errors.Join(errArchived, errForks)
+}
+
+// splitList splits a comma separated list, removing empty items.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// includes returns true if the repository passes the filters of the config.
+func (c githubCrawlerConfig) includes(repo githubRepository) bool {
+	if repo.Archived && !c.includeArchived || repo.Fork && !c.includeForks {
+		return false
+	}
+	hasTopic := func(topic string) bool { return slices.Contains(repo.Topics, strings.ToLower(topic)) }
+	if len(c.topics) > 0 && !slices.ContainsFunc(c.topics, hasTopic) {
+		return false
+	}
+	if slices.ContainsFunc(c.excludeTopics, hasTopic) {
+		return false
+	}
+	isLanguage := func(language string) bool { return strings.EqualFold(language, repo.Language) }
+	if len(c.languages) > 0 && !slices.ContainsFunc(c.languages, isLanguage) {
+		return false
+	}
+	return !slices.ContainsFunc(c.excludeLanguages, isLanguage)
+}
+
+// githubCrawler pages through the repositories of an organization
+// using the GitHub REST API, emitting a target for each repository.
+type githubCrawler struct {
+	config githubCrawlerConfig
+	client *http.Client
+}
+
+// crawlGitHub runs the GitHub organization crawler, emitting a target
+// to the scheduler for the default branch of each repository.
+func crawlGitHub(ctx context.Context) error {
+	config, err := githubCrawlerConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	client, err := runtime.NewCrawlerClientFromEnvironment()
+	if err != nil {
+		return fmt.Errorf("unable to create crawler client: %w", err)
+	}
+	defer process.CloseAndLog(ctx, client)
+
+	crawler := &githubCrawler{config: config, client: &http.Client{Timeout: githubRequestTimeout}}
+	return crawler.crawl(ctx, func(ctx context.Context, target v1beta1.Target) error {
+		name, err := client.EmitTarget(ctx, target)
+		if runtime.ScheduleErrorReasonFor(err) == runtime.ScheduleErrorReasonDuplicate {
+			slog.Info("target already processed", slog.Any("target", target), slog.String("pipeline", name))
+			return nil
+		} else if err != nil {
+			return err
+		}
+		slog.Info("pipeline scheduled for target", slog.Any("target", target), slog.String("pipeline", name))
+		return nil
+	})
+}
+
+// crawl calls emit with the target of each repository of the organization
+// that passes the filters, with the latest commit of its default branch as the version.
+// Empty repositories are skipped. Repositories whose default branch cannot be fetched
+// or that fail to be emitted are skipped, and reported in the returned error.
+func (g *githubCrawler) crawl(ctx context.Context, emit func(context.Context, v1beta1.Target) error) error {
+	l := slog.With(slog.String("organization", g.config.organization))
+	var failed []string
+	next := fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=%d", g.config.apiURL, url.PathEscape(g.config.organization), githubPageSize)
+	for next != "" {
+		var repos []githubRepository
+		header, err := g.get(ctx, next, &repos)
+		if err != nil {
+			return fmt.Errorf("unable to list repositories: %w", err)
+		}
+		next = nextPageURL(header.Get("Link"))
+
+		for _, repo := range repos {
+			if !g.config.includes(repo) {
+				l.Debug("skipping repository excluded by filters", slog.String("repository", repo.FullName))
+				continue
+			}
+			var branch githubBranch
+			branchURL := fmt.Sprintf("%s/repos/%s/branches/%s", g.config.apiURL, repo.FullName, url.PathEscape(repo.DefaultBranch))
+			if _, err = g.get(ctx, branchURL, &branch); err != nil {
+				var statusErr *githubStatusError
+				if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusConflict) {
+					// empty repositories have no default branch
+					l.Info("repository has no default branch, skipping", slog.String("repository", repo.FullName))
+					continue
+				}
+				l.Error("unable to get default branch of repository", slog.String("repository", repo.FullName), slog.Any("error", err))
+				failed = append(failed, repo.FullName)
+				continue
+			}
+			target := v1beta1.Target{Identifier: repo.HTMLURL, Version: branch.Commit.SHA}
+			if err = emit(ctx, target); err != nil {
+				l.Error("unable to emit target for repository", slog.String("repository", repo.FullName), slog.Any("error", err))
+				failed = append(failed, repo.FullName)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to crawl %d repositories: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// get sends a GET request to the GitHub API, decoding the JSON response into v.
+// Requests which are rate limited are retried once the rate limit resets,
+// up to [githubMaxRateLimitRetries] times.
+func (g *githubCrawler) get(ctx context.Context, reqURL string, v any) (http.Header, error) {
+	for attempt := 0; ; attempt++ {
+		header, err := g.getOnce(ctx, reqURL, v)
+		var statusErr *githubStatusError
+		if !errors.As(err, &statusErr) || attempt >= githubMaxRateLimitRetries {
+			return header, err
+		}
+		wait, limited := githubRateLimitWait(statusErr.StatusCode, header, time.Now())
+		if !limited {
+			return header, err
+		}
+		if wait > githubMaxRateLimitWait {
+			return header, fmt.Errorf("rate limit resets in %s: %w", wait.Round(time.Second), err)
+		}
+		slog.Warn("rate limited by GitHub API, waiting to retry", slog.String("url", reqURL), slog.Duration("wait", wait))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return header, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// getOnce sends a single GET request to the GitHub API, decoding the JSON response into v.
+// The header of the response is returned with any error, so rate limits can be checked.
+func (g *githubCrawler) getOnce(ctx context.Context, reqURL string, v any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.config.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.config.token)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer process.CloseAndLog(ctx, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.Header, &githubStatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			URL:        reqURL,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("unable to decode response from %s: %w", reqURL, err)
+	}
+	return resp.Header, nil
+}
+
+// githubRateLimitWait returns how long to wait before retrying a request
+// which failed with the status and header, and false if the request was not rate limited.
+// See https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api
+func githubRateLimitWait(status int, header http.Header, now time.Time) (time.Duration, bool) {
+	if status != http.StatusForbidden && status != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if retryAfter, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(max(retryAfter, 0)) * time.Second, true
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+	if status == http.StatusTooManyRequests {
+		return githubSecondaryRateLimitWait, true
+	}
+	// forbidden without any rate limit headers is a permission error
+	return 0, false
+}
+
+// nextPageURL returns the URL of the next page from the Link header
+// of a response from the GitHub API, or an empty string for the last page.
+func nextPageURL(link string) string {
+	for part := range strings.SplitSeq(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok {
+			continue
+		}
+		for param := range strings.SplitSeq(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
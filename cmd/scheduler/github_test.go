@@ -0,0 +1,338 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+)
+
+// newFakeGitHub serves the repositories of the organization "crashappsec",
+// one per page, and the default branch of each repository.
+func newFakeGitHub(t *testing.T, repos []githubRepository) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("GET /orgs/crashappsec/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var page int
+		if p := r.URL.Query().Get("page"); p != "" {
+			_, _ = fmt.Sscan(p, &page)
+		}
+		if page+1 < len(repos) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/crashappsec/repos?page=%d>; rel="next", <%s/orgs/crashappsec/repos?page=%d>; rel="last"`,
+				server.URL, page+1, server.URL, len(repos)-1))
+		}
+		_ = json.NewEncoder(w).Encode(repos[page : page+1])
+	})
+	mux.HandleFunc("GET /repos/crashappsec/{repo}/branches/{branch}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("repo") == "empty" {
+			http.Error(w, `{"message": "Branch not found"}`, http.StatusNotFound)
+			return
+		}
+		var branch githubBranch
+		branch.Commit.SHA = r.PathValue("repo") + "@" + r.PathValue("branch")
+		_ = json.NewEncoder(w).Encode(branch)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitHubCrawler(t *testing.T) {
+	repo := func(name string, modify func(*githubRepository)) githubRepository {
+		r := githubRepository{
+			FullName:      "crashappsec/" + name,
+			HTMLURL:       "https://github.com/crashappsec/" + name,
+			DefaultBranch: "main",
+			Language:      "Go",
+		}
+		if modify != nil {
+			modify(&r)
+		}
+		return r
+	}
+	server := newFakeGitHub(t, []githubRepository{
+		repo("ocular", func(r *githubRepository) { r.Topics = []string{"security", "kubernetes"} }),
+		repo("chalk", func(r *githubRepository) { r.Language = "Python"; r.DefaultBranch = "release/v1" }),
+		repo("archived", func(r *githubRepository) { r.Archived = true }),
+		repo("fork", func(r *githubRepository) { r.Fork = true }),
+		repo("empty", nil),
+		repo("docs", func(r *githubRepository) { r.Language = "HTML"; r.Topics = []string{"docs"} }),
+	})
+
+	tests := []struct {
+		name     string
+		config   githubCrawlerConfig
+		expected []v1beta1.Target
+	}{
+		{
+			name:   "default filters",
+			config: githubCrawlerConfig{},
+			expected: []v1beta1.Target{
+				{Identifier: "https://github.com/crashappsec/ocular", Version: "ocular@main"},
+				{Identifier: "https://github.com/crashappsec/chalk", Version: "chalk@release/v1"},
+				{Identifier: "https://github.com/crashappsec/docs", Version: "docs@main"},
+			},
+		},
+		{
+			name:   "include archived and forks",
+			config: githubCrawlerConfig{includeArchived: true, includeForks: true, languages: []string{"go"}},
+			expected: []v1beta1.Target{
+				{Identifier: "https://github.com/crashappsec/ocular", Version: "ocular@main"},
+				{Identifier: "https://github.com/crashappsec/archived", Version: "archived@main"},
+				{Identifier: "https://github.com/crashappsec/fork", Version: "fork@main"},
+			},
+		},
+		{
+			name:   "topics",
+			config: githubCrawlerConfig{topics: []string{"Kubernetes", "docs"}, excludeTopics: []string{"docs"}},
+			expected: []v1beta1.Target{
+				{Identifier: "https://github.com/crashappsec/ocular", Version: "ocular@main"},
+			},
+		},
+		{
+			name:   "excluded languages",
+			config: githubCrawlerConfig{excludeLanguages: []string{"html", "python"}},
+			expected: []v1beta1.Target{
+				{Identifier: "https://github.com/crashappsec/ocular", Version: "ocular@main"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.apiURL = server.URL
+			tt.config.organization = "crashappsec"
+			tt.config.token = "test-token"
+			crawler := &githubCrawler{config: tt.config, client: server.Client()}
+
+			var targets []v1beta1.Target
+			err := crawler.crawl(t.Context(), func(_ context.Context, target v1beta1.Target) error {
+				targets = append(targets, target)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(targets, tt.expected) {
+				t.Errorf("expected targets %+v, got %+v", tt.expected, targets)
+			}
+		})
+	}
+
+	t.Run("emit error", func(t *testing.T) {
+		crawler := &githubCrawler{
+			config: githubCrawlerConfig{apiURL: server.URL, organization: "crashappsec", token: "test-token"},
+			client: server.Client(),
+		}
+		var emitted int
+		err := crawler.crawl(t.Context(), func(_ context.Context, target v1beta1.Target) error {
+			emitted++
+			if target.Identifier == "https://github.com/crashappsec/ocular" {
+				return &runtime.ScheduleError{Reason: runtime.ScheduleErrorReasonQuotaExceeded}
+			}
+			return nil
+		})
+		if err == nil || emitted != 3 {
+			t.Errorf("expected error after emitting remaining targets, got %v after %d targets", err, emitted)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		crawler := &githubCrawler{
+			config: githubCrawlerConfig{apiURL: server.URL, organization: "crashappsec"},
+			client: server.Client(),
+		}
+		err := crawler.crawl(t.Context(), func(context.Context, v1beta1.Target) error {
+			return errors.New("no targets expected")
+		})
+		if err == nil {
+			t.Error("expected error listing repositories")
+		}
+	})
+}
+
+func TestGitHubCrawlerRateLimit(t *testing.T) {
+	var repoRequests, rateLimited int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/crashappsec/repos", func(w http.ResponseWriter, r *http.Request) {
+		repoRequests++
+		switch repoRequests {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"message": "You have exceeded a secondary rate limit"}`, http.StatusTooManyRequests)
+			return
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+			http.Error(w, `{"message": "API rate limit exceeded"}`, http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]githubRepository{
+			{FullName: "crashappsec/ocular", HTMLURL: "https://github.com/crashappsec/ocular", DefaultBranch: "main"},
+			{FullName: "crashappsec/empty", HTMLURL: "https://github.com/crashappsec/empty", DefaultBranch: "main"},
+			{FullName: "crashappsec/forbidden", HTMLURL: "https://github.com/crashappsec/forbidden", DefaultBranch: "main"},
+			{FullName: "crashappsec/limited", HTMLURL: "https://github.com/crashappsec/limited", DefaultBranch: "main"},
+		})
+	})
+	mux.HandleFunc("GET /repos/crashappsec/{repo}/branches/{branch}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("repo") {
+		case "empty":
+			http.Error(w, `{"message": "Git Repository is empty."}`, http.StatusConflict)
+			return
+		case "forbidden":
+			http.Error(w, `{"message": "Resource not accessible by integration"}`, http.StatusForbidden)
+			return
+		case "limited":
+			rateLimited++
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"message": "You have exceeded a secondary rate limit"}`, http.StatusTooManyRequests)
+			return
+		}
+		var branch githubBranch
+		branch.Commit.SHA = r.PathValue("repo") + "@" + r.PathValue("branch")
+		_ = json.NewEncoder(w).Encode(branch)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	crawler := &githubCrawler{
+		config: githubCrawlerConfig{apiURL: server.URL, organization: "crashappsec"},
+		client: server.Client(),
+	}
+	var targets []v1beta1.Target
+	err := crawler.crawl(t.Context(), func(_ context.Context, target v1beta1.Target) error {
+		targets = append(targets, target)
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "crashappsec/forbidden") || !strings.Contains(err.Error(), "crashappsec/limited") {
+		t.Errorf("expected error reporting repositories with failed branch lookups, got %v", err)
+	} else if strings.Contains(err.Error(), "crashappsec/empty") {
+		t.Errorf("expected empty repository to be skipped, got %v", err)
+	}
+	expected := []v1beta1.Target{{Identifier: "https://github.com/crashappsec/ocular", Version: "ocular@main"}}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("expected targets %+v, got %+v", expected, targets)
+	}
+	if repoRequests != 3 {
+		t.Errorf("expected repositories to be listed after 2 rate limited requests, got %d requests", repoRequests)
+	}
+	if rateLimited != githubMaxRateLimitRetries+1 {
+		t.Errorf("expected %d requests before giving up on rate limit, got %d", githubMaxRateLimitRetries+1, rateLimited)
+	}
+}
+
+func TestGitHubRateLimitWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name            string
+		status          int
+		header          map[string]string
+		expectedWait    time.Duration
+		expectedLimited bool
+	}{
+		{
+			name:            "retry after",
+			status:          http.StatusTooManyRequests,
+			header:          map[string]string{"Retry-After": "30"},
+			expectedWait:    30 * time.Second,
+			expectedLimited: true,
+		},
+		{
+			name:   "primary rate limit",
+			status: http.StatusForbidden,
+			header: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(now.Add(90*time.Second).Unix(), 10),
+			},
+			expectedWait:    90 * time.Second,
+			expectedLimited: true,
+		},
+		{
+			name:   "reset in the past",
+			status: http.StatusForbidden,
+			header: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(now.Add(-time.Second).Unix(), 10),
+			},
+			expectedLimited: true,
+		},
+		{
+			name:            "secondary rate limit without headers",
+			status:          http.StatusTooManyRequests,
+			expectedWait:    githubSecondaryRateLimitWait,
+			expectedLimited: true,
+		},
+		{
+			name:   "forbidden with remaining requests",
+			status: http.StatusForbidden,
+			header: map[string]string{"X-RateLimit-Remaining": "4999"},
+		},
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			header: map[string]string{"Retry-After": "30"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			wait, limited := githubRateLimitWait(tt.status, header, now)
+			if wait != tt.expectedWait || limited != tt.expectedLimited {
+				t.Errorf("expected wait %s and limited %t, got %s and %t", tt.expectedWait, tt.expectedLimited, wait, limited)
+			}
+		})
+	}
+}
+
+func TestGitHubCrawlerConfigFromEnv(t *testing.T) {
+	t.Setenv(runtime.ParameterToEnvironmentVariable(githubParamOrganization), "crashappsec")
+	t.Setenv(runtime.ParameterToEnvironmentVariable(githubParamAPIURL), "https://github.example.com/api/v3/")
+	t.Setenv(runtime.ParameterToEnvironmentVariable(githubParamIncludeForks), "true")
+	t.Setenv(runtime.ParameterToEnvironmentVariable(githubParamTopics), "security, ,kubernetes")
+	t.Setenv(githubTokenEnvVar, "test-token")
+
+	config, err := githubCrawlerConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := githubCrawlerConfig{
+		apiURL:       "https://github.example.com/api/v3",
+		organization: "crashappsec",
+		token:        "test-token",
+		includeForks: true,
+		topics:       []string{"security", "kubernetes"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected config %+v, got %+v", expected, config)
+	}
+
+	t.Setenv(runtime.ParameterToEnvironmentVariable(githubParamIncludeArchived), "sometimes")
+	if _, err = githubCrawlerConfigFromEnv(); err == nil {
+		t.Error("expected error for invalid boolean parameter")
+	}
+}
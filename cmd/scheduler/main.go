@@ -9,13 +9,18 @@
 // Scheduler is a binary that is loaded into a shared
 // volume mount and wraps crawler executions to provide
 // an interface for crawler to spawn new pipelines/searches.
-// It will create a FIFO for each and then spawn the user process.
-// If the user process writes the FIFO, the respective resource
-// will be created. Once the user process exits and all resources
-// have been scheduled, the program will exit.
+// It will create a FIFO for each and a socket accepting both,
+// then spawn the user process. If the user process writes the FIFO
+// or socket, the respective resource will be created, and requests
+// sent to the socket are answered with the name of the resource.
+// Once the user process exits and all resources have been
+// scheduled, the program will exit.
+// The command "crawl-github" is a built-in crawler, run as the user process,
+// that emits a target for each repository of a GitHub organization.
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -23,6 +28,7 @@ import (
 	"io"
 	"log/slog"
 	"maps"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -33,9 +39,12 @@ import (
 
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/crashappsec/ocular/internal/process"
+	"github.com/crashappsec/ocular/internal/resources"
 	"github.com/crashappsec/ocular/internal/utils"
 	"github.com/crashappsec/ocular/pkg/generated/clientset"
+	"github.com/crashappsec/ocular/pkg/runtime"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
@@ -43,6 +52,10 @@ import (
 	"k8s.io/client-go/util/homedir"
 )
 
+// terminationMessagePath is the path the termination message of the container
+// is written to, read by the kubelet once the container terminates.
+var terminationMessagePath = corev1.TerminationMessagePathDefault
+
 var (
 	version   = "unknown"
 	buildTime = "unknown"
@@ -61,7 +74,7 @@ func main() {
 	l.Info("starting ocular scheduler")
 	if len(os.Args) < 2 {
 		l.Error("no command specified for scheduler")
-		fmt.Println("Usage: scheduler <command> [user command...]")
+		fmt.Println("Usage: scheduler <init|crawler|crawl-github> [user command...]")
 		os.Exit(1)
 	}
 
@@ -110,6 +123,11 @@ func main() {
 			os.Exit(1)
 		}
 		os.Exit(exitCode)
+	case "crawl-github":
+		if err := crawlGitHub(ctx); err != nil {
+			l.Error("unable to crawl github organization", slog.Any("error", err))
+			os.Exit(1)
+		}
 	default:
 		slog.Error("unknown command")
 		os.Exit(1)
@@ -130,6 +148,7 @@ func Schedule(ctx context.Context) (process.Hook, error) {
 	namespace := os.Getenv(v1beta1.EnvVarNamespaceName)
 	pipelineFIFOPath := os.Getenv(v1beta1.EnvVarPipelineFIFO)
 	searchFIFOPath := os.Getenv(v1beta1.EnvVarSearchFIFO)
+	socketPath := os.Getenv(v1beta1.EnvVarSchedulerSocket)
 
 	templateFilePath := os.Getenv(v1beta1.EnvVarPipelineTemplatePath)
 	sleepDuration, err := strconv.Atoi(os.Getenv(v1beta1.EnvVarPipelineSchedulerIntervalSeconds))
@@ -147,14 +166,15 @@ func Schedule(ctx context.Context) (process.Hook, error) {
 		return nil, fmt.Errorf("unable to open pipeline template file")
 	}
 
-	var template v1beta1.PipelineTemplate
-	if err = json.NewDecoder(templateFile).Decode(&template); err != nil {
-		slog.Error("unable to decode pipeline template", slog.String("file", templateFilePath), slog.Any("error", err))
-		return nil, fmt.Errorf("unable to decode pipeline template")
+	var templates resources.SchedulerPipelineTemplates
+	if err = json.NewDecoder(templateFile).Decode(&templates); err != nil {
+		slog.Error("unable to decode pipeline templates", slog.String("file", templateFilePath), slog.Any("error", err))
+		return nil, fmt.Errorf("unable to decode pipeline templates")
 	}
 	slog.Info("creating FIFOs", slog.String("pipeline-fifo", pipelineFIFOPath), slog.String("search-fifo", searchFIFOPath))
 
-	targets, crawlers := make(chan v1beta1.Target), make(chan v1beta1.ParameterizedLocalObjectReference)
+	targets := make(chan scheduleItem[runtime.PipelineRequest])
+	crawlers := make(chan scheduleItem[v1beta1.ParameterizedLocalObjectReference])
 
 	if err = createFIFO(ctx, pipelineFIFOPath); err != nil {
 		return nil, fmt.Errorf("unable to create pipeline FIFO")
@@ -165,6 +185,26 @@ func Schedule(ctx context.Context) (process.Hook, error) {
 		return nil, fmt.Errorf("unable to create search FIFO")
 	}
 
+	var listener net.Listener
+	if socketPath != "" {
+		slog.Info("creating scheduler socket", slog.String("socket", socketPath))
+		if listener, err = listenSocket(ctx, socketPath); err != nil {
+			slog.Error("unable to create scheduler socket", slog.String("socket", socketPath), slog.Any("error", err))
+			utils.RemoveAndLog(ctx, pipelineFIFOPath)
+			utils.RemoveAndLog(ctx, searchFIFOPath)
+			return nil, fmt.Errorf("unable to create scheduler socket")
+		}
+	}
+
+	dedup := parseDeduplicatorFromEnv(cs, namespace)
+	overridePolicy := parseOverridePolicyFromEnv()
+	successPolicy := parseSuccessPolicyFromEnv()
+
+	maxActivePipelines := parseLimitFromEnv(v1beta1.EnvVarPipelineSchedulerMaxActivePipelines)
+	maxActiveSearches := parseLimitFromEnv(v1beta1.EnvVarPipelineSchedulerMaxActiveSearches)
+	pipelineLimiter := newPipelineLimiter(cs, namespace, searchName, maxActivePipelines)
+	searchLimiter := newSearchLimiter(cs, namespace, searchName, maxActiveSearches)
+
 	// crawlerCtx will have an event sent to the Done channel
 	// when the crawler container exits for this search.
 	crawlerCtx, crawlerCancel := context.WithCancel(ctx)
@@ -180,13 +220,33 @@ func Schedule(ctx context.Context) (process.Hook, error) {
 		Controller: new(true),
 	}
 
+	// each count is only written by its scheduler and read once the schedulers are done
+	var counts resources.SchedulerTerminationMessage
+
 	slog.Info("starting workers")
 	wg := &sync.WaitGroup{}
 
-	// crawler decoder and scheduler
-	wg.Go(func() {
+	// producers read from the FIFOs and socket until the crawler exits,
+	// after which the channels are closed to stop the schedulers
+	producers := &sync.WaitGroup{}
+	producers.Go(func() {
 		fifoDecoder(crawlerCtx, crawlers, searchFIFOPath)
 	})
+	producers.Go(func() {
+		fifoDecoder(crawlerCtx, targets, pipelineFIFOPath)
+	})
+	if listener != nil {
+		producers.Go(func() {
+			serveSocket(crawlerCtx, listener, targets, crawlers)
+		})
+	}
+	wg.Go(func() {
+		producers.Wait()
+		close(targets)
+		close(crawlers)
+	})
+
+	// crawler scheduler
 	wg.Go(func() {
 		var ttlSeconds *int32
 		if ttlEnv := os.Getenv(v1beta1.EnvVarSchedulerSearchTTL); ttlEnv != "" {
@@ -197,11 +257,17 @@ func Schedule(ctx context.Context) (process.Hook, error) {
 		}
 		serviceAccount := os.Getenv(v1beta1.EnvVarSchedulerServiceAccount)
 		for {
-			crawler, ok := <-crawlers
+			item, ok := <-crawlers
+			crawler := item.value
 			if !ok {
 				slog.Info("crawler channel closed")
 				break
 			}
+			if searchLimiter != nil {
+				if err := searchLimiter.Wait(ctx); err != nil {
+					slog.Error("unable to wait for active searches, scheduling anyway", slog.Any("error", err))
+				}
+			}
 			slog.Info("scheduling search for crawler", slog.Any("crawler", crawler))
 			search := &v1beta1.Search{
 				ObjectMeta: metav1.ObjectMeta{
@@ -215,65 +281,147 @@ func Schedule(ctx context.Context) (process.Hook, error) {
 				Spec: v1beta1.SearchSpec{
 					TTLSecondsAfterFinished: ttlSeconds,
 					ServiceAccountName:      serviceAccount,
+					SuccessPolicy:           successPolicy.DeepCopy(),
 					Scheduler: v1beta1.SearchSchedulerSpec{
-						IntervalSeconds: new(int32(sleepDuration)),
+						IntervalSeconds:    new(int32(sleepDuration)),
+						MaxActivePipelines: limitSpec(maxActivePipelines),
+						MaxActiveSearches:  limitSpec(maxActiveSearches),
+						Deduplication: &v1beta1.PipelineDeduplicationSpec{
+							Policy: dedup.policy,
+						},
+						Overrides: overridePolicy.DeepCopy(),
 					},
 					CrawlerRef: v1beta1.ParameterizedLocalObjectReference{},
 				},
 			}
+			if dedup.window > 0 {
+				search.Spec.Scheduler.Deduplication.WindowSeconds = new(int32(dedup.window.Seconds()))
+			}
 			crawler.DeepCopyInto(&search.Spec.CrawlerRef)
-			template.Spec.DeepCopyInto(&search.Spec.Scheduler.PipelineTemplate.Spec)
+			templates.ApplyTo(&search.Spec.Scheduler)
 			slog.Info("starting search", slog.Any("search", search))
 			scheduledSearch, err := cs.ApiV1beta1().Searches(namespace).Create(ctx, search, metav1.CreateOptions{})
 			if err != nil {
 				slog.Error("unable to start pipeline for crawler", slog.Any("crawler", crawler), slog.Any("error", err))
+				counts.FailedSearches++
+				item.respond("", scheduleErrorFor(err))
 				continue
 			}
 			slog.Info("search created", "search", scheduledSearch.Name)
+			counts.CreatedSearches++
+			item.respond(scheduledSearch.Name, nil)
 
-			time.Sleep(time.Duration(sleepDuration) * time.Second)
+			if searchLimiter == nil {
+				time.Sleep(time.Duration(sleepDuration) * time.Second)
+			}
 		}
 		slog.Info("search scheduler complete")
 	})
 
-	wg.Go(func() {
-		// pipeline decoder and scheduler
-		fifoDecoder(crawlerCtx, targets, pipelineFIFOPath)
-	})
+	// createPipeline creates the pipeline for the target from the template, returning
+	// the name of the pipeline, or the existing pipeline if the target is a duplicate.
+	createPipeline := func(template v1beta1.NamedPipelineTemplate, request runtime.PipelineRequest) (string, *runtime.ScheduleError) {
+		target := request.Target
+		pipeline := &v1beta1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: searchName + "-",
+				Namespace:    namespace,
+				Annotations:  maps.Clone(template.Annotations),
+				Labels:       utils.MergeMaps(template.Labels, scheduledByLabels),
+				OwnerReferences: []metav1.OwnerReference{
+					*ownerRef.DeepCopy(),
+				},
+			},
+		}
+		if template.Name != "" {
+			pipeline.Labels[v1beta1.PipelineTemplateLabelKey] = template.Name
+		}
+
+		template.Spec.DeepCopyInto(&pipeline.Spec)
+		request.Overrides.ApplyTo(pipeline)
+
+		target.DeepCopyInto(&pipeline.Spec.Target)
+
+		targetHash := resources.TargetHash(pipeline.Spec)
+		pipeline.Labels[v1beta1.TargetHashLabelKey] = targetHash
+		existing, err := dedup.ShouldSkip(ctx, targetHash)
+		if err != nil {
+			slog.Error("unable to check for existing pipelines, scheduling anyway", slog.Any("target", target), slog.Any("error", err))
+		} else if existing != "" {
+			slog.Info("skipping target already processed by existing pipeline", slog.Any("target", target),
+				slog.String("template", template.Name), slog.String("pipeline", existing), slog.String("policy", string(dedup.policy)))
+			return existing, &runtime.ScheduleError{
+				Reason:  runtime.ScheduleErrorReasonDuplicate,
+				Message: fmt.Sprintf("target already processed by pipeline %s", existing),
+			}
+		}
+
+		if pipelineLimiter != nil {
+			if err := pipelineLimiter.Wait(ctx); err != nil {
+				slog.Error("unable to wait for active pipelines, scheduling anyway", slog.Any("error", err))
+			}
+		}
+
+		scheduledPipeline, err := cs.ApiV1beta1().Pipelines(namespace).Create(ctx, pipeline, metav1.CreateOptions{})
+		if err != nil {
+			slog.Error("unable to start pipeline for target", slog.Any("target", target), slog.String("template", template.Name), slog.Any("error", err))
+			return "", scheduleErrorFor(err)
+		}
+		slog.Info("pipeline created", "pipeline", scheduledPipeline.Name, "template", template.Name)
+		return scheduledPipeline.Name, nil
+	}
 
+	// pipeline scheduler
 	wg.Go(func() {
-		pipelineLabels := utils.MergeMaps(template.Labels, scheduledByLabels)
-		pipelineAnnotations := template.Annotations
 		for {
-			target, ok := <-targets
+			item, ok := <-targets
+			request := item.value
 			if !ok {
 				slog.Info("target channel closed")
 				break
 			}
+			target := request.Target
 
-			slog.Info("scheduling pipeline for target", "target", target)
-			pipeline := &v1beta1.Pipeline{
-				ObjectMeta: metav1.ObjectMeta{
-					GenerateName: searchName + "-",
-					Namespace:    namespace,
-					Annotations:  maps.Clone(pipelineAnnotations),
-					Labels:       maps.Clone(pipelineLabels),
-					OwnerReferences: []metav1.OwnerReference{
-						*ownerRef.DeepCopy(),
-					},
-				},
+			slog.Info("scheduling pipeline for target", "target", target, "templates", request.Templates, "overrides", request.Overrides)
+			if scheduleErr := validateOverrides(overridePolicy, request.Overrides); scheduleErr != nil {
+				slog.Error("rejecting target with overrides not allowed by search", slog.Any("target", target), slog.Any("error", scheduleErr))
+				counts.FailedPipelines++
+				item.respond("", scheduleErr)
+				continue
 			}
-
-			template.Spec.DeepCopyInto(&pipeline.Spec)
-
-			target.DeepCopyInto(&pipeline.Spec.Target)
-			scheduledPipeline, err := cs.ApiV1beta1().Pipelines(namespace).Create(ctx, pipeline, metav1.CreateOptions{})
+			selected, err := templates.Select(request.Templates)
 			if err != nil {
-				slog.Error("unable to start pipeline for target", slog.Any("target", target), slog.Any("error", err))
+				slog.Error("rejecting target with unknown pipeline templates", slog.Any("target", target), slog.Any("error", err))
+				counts.FailedPipelines++
+				item.respond("", &runtime.ScheduleError{Reason: runtime.ScheduleErrorReasonInvalidRequest, Message: err.Error()})
 				continue
 			}
-			slog.Info("pipeline created", "pipeline", scheduledPipeline.Name)
-			time.Sleep(time.Duration(sleepDuration) * time.Second)
+
+			var (
+				names    = make([]string, len(selected))
+				firstErr *runtime.ScheduleError
+				created  bool
+			)
+			for i, template := range selected {
+				if created && pipelineLimiter == nil {
+					time.Sleep(time.Duration(sleepDuration) * time.Second)
+				}
+				name, scheduleErr := createPipeline(template, request)
+				names[i] = name
+				if scheduleErr != nil {
+					firstErr = cmp.Or(firstErr, scheduleErr)
+					if scheduleErr.Reason != runtime.ScheduleErrorReasonDuplicate {
+						counts.FailedPipelines++
+					}
+				} else {
+					created = true
+					counts.CreatedPipelines++
+				}
+			}
+			item.respondPipelines(names, firstErr)
+			if created && pipelineLimiter == nil {
+				time.Sleep(time.Duration(sleepDuration) * time.Second)
+			}
 		}
 		slog.Info("pipeline scheduler complete")
 	})
@@ -281,12 +429,29 @@ func Schedule(ctx context.Context) (process.Hook, error) {
 	return func(ctx context.Context, _ *exec.Cmd) error {
 		crawlerCancel()
 		wg.Wait()
+		slog.Info("scheduling complete",
+			slog.Int("created-pipelines", int(counts.CreatedPipelines)), slog.Int("failed-pipelines", int(counts.FailedPipelines)),
+			slog.Int("created-searches", int(counts.CreatedSearches)), slog.Int("failed-searches", int(counts.FailedSearches)))
+		if err := writeTerminationMessage(counts); err != nil {
+			slog.Error("unable to write termination message", slog.Any("error", err))
+		}
+		// the socket is removed when the listener is closed
 		utils.RemoveAndLog(ctx, pipelineFIFOPath)
 		utils.RemoveAndLog(ctx, searchFIFOPath)
 		return nil
 	}, nil
 }
 
+// writeTerminationMessage writes the message as JSON to the termination message of the
+// crawler container, overwriting any message written by the crawler.
+func writeTerminationMessage(message resources.SchedulerTerminationMessage) error {
+	contents, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("unable to marshal termination message: %w", err)
+	}
+	return os.WriteFile(terminationMessagePath, contents, 0o644)
+}
+
 func createFIFO(_ context.Context, path string) error {
 	if err := syscall.Mkfifo(path, 0622); err != nil {
 		return fmt.Errorf("unable to create FIFO: %w", err)
@@ -310,7 +475,7 @@ func openFIFOReader(_ context.Context, path string) (io.ReadCloser, error) {
 
 }
 
-func fifoDecoder[T any](ctx context.Context, c chan T, path string) {
+func fifoDecoder[T any](ctx context.Context, c chan<- scheduleItem[T], path string) {
 	var (
 		decoder *json.Decoder
 		rc      io.ReadCloser
@@ -340,13 +505,12 @@ func fifoDecoder[T any](ctx context.Context, c chan T, path string) {
 			time.Sleep(time.Second)
 		} else {
 			l.Info("decoded from reader", slog.Any("t", t))
-			c <- t
+			c <- scheduleItem[T]{value: t}
 			continue
 		}
 		select {
 		case <-ctx.Done():
 			l.Info("received signal, exiting decoder")
-			close(c)
 			return
 		default:
 		}
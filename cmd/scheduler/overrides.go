@@ -0,0 +1,68 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/validators"
+	"github.com/crashappsec/ocular/pkg/runtime"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// parseOverridePolicyFromEnv returns the override policy of the search,
+// or nil if the search allows no overrides.
+func parseOverridePolicyFromEnv() *v1beta1.PipelineOverridePolicy {
+	value := os.Getenv(v1beta1.EnvVarPipelineOverridePolicy)
+	if value == "" {
+		return nil
+	}
+	var policy v1beta1.PipelineOverridePolicy
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		slog.Error("unable to parse override policy, disallowing overrides", slog.String("policy", value), slog.Any("error", err))
+		return nil
+	}
+	return &policy
+}
+
+// parseSuccessPolicyFromEnv returns the success policy of the search,
+// or nil if the search has no policy.
+func parseSuccessPolicyFromEnv() *v1beta1.SearchSuccessPolicy {
+	value := os.Getenv(v1beta1.EnvVarSearchSuccessPolicy)
+	if value == "" {
+		return nil
+	}
+	var policy v1beta1.SearchSuccessPolicy
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		slog.Error("unable to parse success policy, using default for searches", slog.String("policy", value), slog.Any("error", err))
+		return nil
+	}
+	return &policy
+}
+
+// validateOverrides returns the [runtime.ScheduleError] for overrides
+// that are not allowed by the policy, or nil if they are allowed.
+func validateOverrides(policy *v1beta1.PipelineOverridePolicy, overrides *runtime.PipelineOverrides) *runtime.ScheduleError {
+	errs := validators.ValidatePipelineOverrides(policy, overrides, field.NewPath("overrides"))
+	if len(errs) == 0 {
+		return nil
+	}
+	reason := runtime.ScheduleErrorReasonInvalidRequest
+	for _, err := range errs {
+		if err.Type == field.ErrorTypeForbidden {
+			reason = runtime.ScheduleErrorReasonOverrideNotAllowed
+			break
+		}
+	}
+	return &runtime.ScheduleError{Reason: reason, Message: errs.ToAggregate().Error()}
+}
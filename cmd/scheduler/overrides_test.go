@@ -0,0 +1,138 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+)
+
+func TestValidateOverrides(t *testing.T) {
+	policy := &v1beta1.PipelineOverridePolicy{
+		Profiles:          []v1beta1.AllowedReference{{Name: "go"}, {Name: "js"}},
+		Downloaders:       []v1beta1.AllowedReference{{Name: "git", Kind: "ClusterDownloader"}},
+		ProfileParameters: []string{"DEPTH"},
+		Labels:            []string{"team"},
+		Annotations:       []string{"example.com/language"},
+	}
+	tests := []struct {
+		name      string
+		policy    *v1beta1.PipelineOverridePolicy
+		overrides *runtime.PipelineOverrides
+		expected  runtime.ScheduleErrorReason
+	}{
+		{
+			name:   "no overrides",
+			policy: nil,
+		},
+		{
+			name:   "allowed",
+			policy: policy,
+			overrides: &runtime.PipelineOverrides{
+				ProfileRef:        &v1beta1.ParameterizedLocalObjectReference{Name: "js", Kind: "Profile"},
+				DownloaderRef:     &v1beta1.ParameterizedLocalObjectReference{Name: "git", Kind: "ClusterDownloader"},
+				ProfileParameters: []v1beta1.ParameterSetting{{Name: "DEPTH", Value: "1"}},
+				Labels:            map[string]string{"team": "web"},
+				Annotations:       map[string]string{"example.com/language": "TypeScript"},
+			},
+		},
+		{
+			name:      "no policy",
+			policy:    nil,
+			overrides: &runtime.PipelineOverrides{ProfileRef: &v1beta1.ParameterizedLocalObjectReference{Name: "go"}},
+			expected:  runtime.ScheduleErrorReasonOverrideNotAllowed,
+		},
+		{
+			name:      "downloader of other kind",
+			policy:    policy,
+			overrides: &runtime.PipelineOverrides{DownloaderRef: &v1beta1.ParameterizedLocalObjectReference{Name: "git"}},
+			expected:  runtime.ScheduleErrorReasonOverrideNotAllowed,
+		},
+		{
+			name:   "parameter of allowed profile",
+			policy: policy,
+			overrides: &runtime.PipelineOverrides{ProfileRef: &v1beta1.ParameterizedLocalObjectReference{
+				Name:       "go",
+				Parameters: []v1beta1.ParameterSetting{{Name: "SECRET", Value: "x"}},
+			}},
+			expected: runtime.ScheduleErrorReasonOverrideNotAllowed,
+		},
+		{
+			name:      "label",
+			policy:    policy,
+			overrides: &runtime.PipelineOverrides{Labels: map[string]string{"owner": "me"}},
+			expected:  runtime.ScheduleErrorReasonOverrideNotAllowed,
+		},
+		{
+			name:      "invalid label value",
+			policy:    policy,
+			overrides: &runtime.PipelineOverrides{Labels: map[string]string{"team": "not a label value"}},
+			expected:  runtime.ScheduleErrorReasonInvalidRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleErr := validateOverrides(tt.policy, tt.overrides)
+			var reason runtime.ScheduleErrorReason
+			if scheduleErr != nil {
+				reason = scheduleErr.Reason
+			}
+			if reason != tt.expected {
+				t.Errorf("expected reason %q, got %v", tt.expected, scheduleErr)
+			}
+		})
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	pipeline := &v1beta1.Pipeline{}
+	pipeline.Labels = map[string]string{v1beta1.ScheduledByLabelKey: "search"}
+	pipeline.Spec.ProfileRef = v1beta1.ParameterizedLocalObjectReference{
+		Name:       "default",
+		Parameters: []v1beta1.ParameterSetting{{Name: "DEPTH", Value: "10"}, {Name: "MODE", Value: "fast"}},
+	}
+	pipeline.Spec.DownloaderRef = v1beta1.ParameterizedLocalObjectReference{Name: "git"}
+
+	overrides := &runtime.PipelineOverrides{
+		ProfileRef:           &v1beta1.ParameterizedLocalObjectReference{Name: "go", Parameters: []v1beta1.ParameterSetting{{Name: "DEPTH", Value: "5"}}},
+		ProfileParameters:    []v1beta1.ParameterSetting{{Name: "DEPTH", Value: "1"}, {Name: "LINT", Value: "true"}},
+		DownloaderParameters: []v1beta1.ParameterSetting{{Name: "BRANCH", Value: "main"}},
+		Labels:               map[string]string{"team": "web"},
+		Annotations:          map[string]string{"example.com/language": "Go"},
+	}
+	overrides.ApplyTo(pipeline)
+
+	wantProfile := v1beta1.ParameterizedLocalObjectReference{
+		Name:       "go",
+		Parameters: []v1beta1.ParameterSetting{{Name: "DEPTH", Value: "1"}, {Name: "LINT", Value: "true"}},
+	}
+	if !reflect.DeepEqual(pipeline.Spec.ProfileRef, wantProfile) {
+		t.Errorf("expected profile %+v, got %+v", wantProfile, pipeline.Spec.ProfileRef)
+	}
+	wantDownloader := v1beta1.ParameterizedLocalObjectReference{
+		Name:       "git",
+		Parameters: []v1beta1.ParameterSetting{{Name: "BRANCH", Value: "main"}},
+	}
+	if !reflect.DeepEqual(pipeline.Spec.DownloaderRef, wantDownloader) {
+		t.Errorf("expected downloader %+v, got %+v", wantDownloader, pipeline.Spec.DownloaderRef)
+	}
+	wantLabels := map[string]string{v1beta1.ScheduledByLabelKey: "search", "team": "web"}
+	if !reflect.DeepEqual(pipeline.Labels, wantLabels) {
+		t.Errorf("expected labels %v, got %v", wantLabels, pipeline.Labels)
+	}
+	if pipeline.Annotations["example.com/language"] != "Go" {
+		t.Errorf("expected annotation to be set, got %v", pipeline.Annotations)
+	}
+	if overrides.ProfileRef.Parameters[0].Value != "5" {
+		t.Error("expected overrides to not be modified")
+	}
+}
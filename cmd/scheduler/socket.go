@@ -0,0 +1,168 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/process"
+	"github.com/crashappsec/ocular/pkg/runtime"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// scheduleItem is a value read from a FIFO or the scheduler socket
+// to create a pipeline or search for.
+type scheduleItem[T any] struct {
+	value T
+	// reply receives the result of scheduling the value,
+	// nil if the value was read from a FIFO.
+	reply chan<- runtime.ScheduleResponse
+}
+
+// respond sends the name of the created or existing resource and the error, if any,
+// to the client that sent the item. It must be called once for every item.
+func (i scheduleItem[T]) respond(name string, err *runtime.ScheduleError) {
+	if i.reply != nil {
+		i.reply <- runtime.ScheduleResponse{Name: name, Error: err}
+	}
+}
+
+// respondPipelines sends the names of the pipelines created or existing for each selected
+// pipeline template and the first error, if any, to the client that sent the target.
+func (i scheduleItem[T]) respondPipelines(names []string, err *runtime.ScheduleError) {
+	if i.reply != nil {
+		i.reply <- runtime.ScheduleResponse{Name: cmp.Or(names...), Names: names, Error: err}
+	}
+}
+
+// scheduleErrorFor converts an error returned when creating a resource to a [runtime.ScheduleError].
+func scheduleErrorFor(err error) *runtime.ScheduleError {
+	reason := runtime.ScheduleErrorReasonInternalError
+	if apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota") {
+		reason = runtime.ScheduleErrorReasonQuotaExceeded
+	} else if statusReason := apierrors.ReasonForError(err); statusReason != metav1.StatusReasonUnknown {
+		reason = runtime.ScheduleErrorReason(statusReason)
+	}
+	return &runtime.ScheduleError{Reason: reason, Message: err.Error()}
+}
+
+// listenSocket creates the scheduler socket at path, which crawlers must be able to connect to.
+func listenSocket(_ context.Context, path string) (net.Listener, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on socket: %w", err)
+	}
+	if err = os.Chmod(path, 0622); err != nil {
+		slog.Error("failed to change permissions of socket", slog.String("path", path), slog.Any("error", err))
+	}
+	return listener, nil
+}
+
+// serveSocket accepts connections on the listener until ctx is done, sending the requests
+// of each connection to targets or crawlers and writing the response once it is received.
+// It returns once the listener and every connection have been closed.
+func serveSocket(
+	ctx context.Context,
+	listener net.Listener,
+	targets chan<- scheduleItem[runtime.PipelineRequest],
+	crawlers chan<- scheduleItem[v1beta1.ParameterizedLocalObjectReference],
+) {
+	l := slog.With(slog.String("socket", listener.Addr().String()))
+	stop := context.AfterFunc(ctx, func() {
+		l.Info("received signal, closing socket")
+		process.CloseAndLog(ctx, listener)
+	})
+	defer stop()
+
+	conns := &sync.WaitGroup{}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				l.Error("unable to accept connection", slog.Any("error", err))
+				process.CloseAndLog(ctx, listener)
+			}
+			break
+		}
+		conns.Go(func() {
+			// a crawler that exits without closing its connection
+			// should not keep the scheduler from completing
+			stopConn := context.AfterFunc(ctx, func() { _ = conn.SetReadDeadline(time.Now()) })
+			defer stopConn()
+			defer process.CloseAndLog(ctx, conn)
+			serveConn(l, conn, targets, crawlers)
+		})
+	}
+	conns.Wait()
+}
+
+// serveConn handles the requests of a single connection to the scheduler socket, in order.
+func serveConn(
+	l *slog.Logger,
+	conn io.ReadWriter,
+	targets chan<- scheduleItem[runtime.PipelineRequest],
+	crawlers chan<- scheduleItem[v1beta1.ParameterizedLocalObjectReference],
+) {
+	decoder, encoder := json.NewDecoder(conn), json.NewEncoder(conn)
+	reply := make(chan runtime.ScheduleResponse, 1)
+	for {
+		var request runtime.ScheduleRequest
+		err := decoder.Decode(&request)
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+			// the rest of the stream cannot be decoded, so the connection is closed after responding
+			l.Error("unable to decode request", slog.Any("error", err))
+			_ = encoder.Encode(runtime.ScheduleResponse{
+				ID:    request.ID,
+				Error: &runtime.ScheduleError{Reason: runtime.ScheduleErrorReasonInvalidRequest, Message: err.Error()},
+			})
+			return
+		} else if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrDeadlineExceeded) {
+				l.Error("error from decoder", slog.Any("error", err))
+			}
+			return
+		}
+
+		var response runtime.ScheduleResponse
+		switch {
+		case (request.Pipeline == nil) == (request.Search == nil):
+			response.Error = &runtime.ScheduleError{
+				Reason:  runtime.ScheduleErrorReasonInvalidRequest,
+				Message: "exactly one of pipeline or search must be set",
+			}
+		case request.Pipeline != nil:
+			targets <- scheduleItem[runtime.PipelineRequest]{value: *request.Pipeline, reply: reply}
+			response = <-reply
+		default:
+			crawlers <- scheduleItem[v1beta1.ParameterizedLocalObjectReference]{value: *request.Search, reply: reply}
+			response = <-reply
+		}
+		response.ID = request.ID
+		if err = encoder.Encode(response); err != nil {
+			l.Error("unable to write response", slog.Uint64("id", request.ID), slog.Any("error", err))
+			return
+		}
+	}
+}
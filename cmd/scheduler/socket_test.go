@@ -0,0 +1,136 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestScheduleErrorFor(t *testing.T) {
+	pipelines := schema.GroupResource{Group: v1beta1.GroupVersion.Group, Resource: "pipelines"}
+	tests := []struct {
+		name     string
+		err      error
+		expected runtime.ScheduleErrorReason
+	}{
+		{
+			name:     "quota exceeded",
+			err:      apierrors.NewForbidden(pipelines, "", errors.New("exceeded quota: pipelines, requested: count/pipelines=1")),
+			expected: runtime.ScheduleErrorReasonQuotaExceeded,
+		},
+		{
+			name:     "forbidden",
+			err:      apierrors.NewForbidden(pipelines, "", errors.New("cannot create resource")),
+			expected: runtime.ScheduleErrorReason("Forbidden"),
+		},
+		{
+			name:     "invalid",
+			err:      apierrors.NewBadRequest("invalid target"),
+			expected: runtime.ScheduleErrorReason("BadRequest"),
+		},
+		{
+			name:     "not an api error",
+			err:      errors.New("connection refused"),
+			expected: runtime.ScheduleErrorReasonInternalError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleErr := scheduleErrorFor(tt.err)
+			if scheduleErr.Reason != tt.expected {
+				t.Errorf("expected reason %s, got %s", tt.expected, scheduleErr.Reason)
+			}
+			if scheduleErr.Message != tt.err.Error() {
+				t.Errorf("expected message %q, got %q", tt.err.Error(), scheduleErr.Message)
+			}
+		})
+	}
+}
+
+func TestServeSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "scheduler.sock")
+	listener, err := listenSocket(t.Context(), socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	targets := make(chan scheduleItem[runtime.PipelineRequest])
+	crawlers := make(chan scheduleItem[v1beta1.ParameterizedLocalObjectReference])
+	wg := &sync.WaitGroup{}
+	wg.Go(func() {
+		serveSocket(ctx, listener, targets, crawlers)
+		close(targets)
+		close(crawlers)
+	})
+	wg.Go(func() {
+		for item := range targets {
+			if item.value.Identifier == "duplicate" {
+				item.respond("existing", &runtime.ScheduleError{Reason: runtime.ScheduleErrorReasonDuplicate})
+				continue
+			}
+			item.respond("pipeline-"+item.value.Identifier, nil)
+		}
+	})
+	wg.Go(func() {
+		for item := range crawlers {
+			item.respond("", scheduleErrorFor(apierrors.NewForbidden(
+				schema.GroupResource{Resource: "searches"}, "", errors.New("exceeded quota: searches"))))
+		}
+	})
+
+	client := runtime.NewSocketCrawlerClient(socketPath)
+	name, err := client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "a"})
+	if err != nil || name != "pipeline-a" {
+		t.Errorf("expected pipeline-a to be created, got %q, %v", name, err)
+	}
+	name, err = client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "duplicate"})
+	if runtime.ScheduleErrorReasonFor(err) != runtime.ScheduleErrorReasonDuplicate || name != "existing" {
+		t.Errorf("expected duplicate of existing, got %q, %v", name, err)
+	}
+	_, err = client.EmitSearch(t.Context(), v1beta1.ParameterizedLocalObjectReference{Name: "crawler"})
+	if runtime.ScheduleErrorReasonFor(err) != runtime.ScheduleErrorReasonQuotaExceeded {
+		t.Errorf("expected quota exceeded, got %v", err)
+	}
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err = conn.Write([]byte(`{"id":7}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	var response runtime.ScheduleResponse
+	if err = json.NewDecoder(conn).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.ID != 7 || response.Error == nil || response.Error.Reason != runtime.ScheduleErrorReasonInvalidRequest {
+		t.Errorf("expected invalid request response, got %+v", response)
+	}
+
+	// the connections are left open, as a crawler that exits without closing it would
+	cancel()
+	wg.Wait()
+	if err = client.Close(); err != nil {
+		t.Error(err)
+	}
+}
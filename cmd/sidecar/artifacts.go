@@ -0,0 +1,230 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/resources"
+	"github.com/crashappsec/ocular/pkg/runtime"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// terminationMessagePath is the path the termination message of the container
+// is written to, read by the kubelet once the container terminates.
+var terminationMessagePath = corev1.TerminationMessagePathDefault
+
+const (
+	// maxTerminationMessageSize is the size the kubelet truncates
+	// the termination message of a container to.
+	maxTerminationMessageSize = 4096
+	// maxTerminationMessageErrorLength is the length errors of artifacts are
+	// shortened to when the termination message would otherwise be too large.
+	maxTerminationMessageErrorLength = 128
+)
+
+// terminationMessageReductions remove detail from the artifacts of the termination message,
+// applied in order until the message fits in [maxTerminationMessageSize]. The total number
+// of findings of each artifact is always kept.
+var terminationMessageReductions = []func(*v1beta1.PipelineArtifactStatus){
+	func(a *v1beta1.PipelineArtifactStatus) {
+		if a.Findings != nil {
+			a.Findings.ByRule = nil
+		}
+	},
+	func(a *v1beta1.PipelineArtifactStatus) {
+		if len(a.Error) > maxTerminationMessageErrorLength {
+			a.Error = a.Error[:maxTerminationMessageErrorLength] + "..."
+		}
+	},
+	func(a *v1beta1.PipelineArtifactStatus) {
+		if a.Findings != nil {
+			a.Findings.BySeverity = nil
+		}
+	},
+}
+
+// ArtifactChecksFromEnvironment parses the artifact checks from [v1beta1.EnvVarArtifactChecks].
+func ArtifactChecksFromEnvironment() ([]v1beta1.ArtifactCheck, error) {
+	value := os.Getenv(v1beta1.EnvVarArtifactChecks)
+	if value == "" {
+		return nil, nil
+	}
+	var checks []v1beta1.ArtifactCheck
+	if err := json.Unmarshal([]byte(value), &checks); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", v1beta1.EnvVarArtifactChecks, err)
+	}
+	return checks, nil
+}
+
+// CheckArtifacts checks that each of the artifacts exists, and
+// passes the [v1beta1.ArtifactCheck] for its path if there is one.
+func CheckArtifacts(artifacts []string, checks []v1beta1.ArtifactCheck) []runtime.ArtifactResult {
+	results := make([]runtime.ArtifactResult, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		result := runtime.ArtifactResult{Path: artifact}
+		i := slices.IndexFunc(checks, func(c v1beta1.ArtifactCheck) bool { return c.Path == artifact })
+		var check v1beta1.ArtifactCheck
+		if i >= 0 {
+			check = checks[i]
+		}
+		if err := checkArtifact(&result, check); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkArtifact populates the result for the artifact at result.Path,
+// returning an error if the artifact is missing or fails the check.
+// The findings of SARIF and CycloneDX artifacts are summarized once the check passes.
+func checkArtifact(result *runtime.ArtifactResult, check v1beta1.ArtifactCheck) error {
+	info, err := os.Stat(result.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("artifact does not exist")
+	} else if err != nil {
+		return fmt.Errorf("unable to stat artifact: %w", err)
+	}
+	result.Present = true
+	result.SizeBytes = info.Size()
+	if info.IsDir() {
+		// directories are passed as is, only files can have their content checked
+		return nil
+	}
+
+	if check.MaxSize != nil && info.Size() > check.MaxSize.Value() {
+		return fmt.Errorf("artifact is %d bytes, larger than the maximum of %s", info.Size(), check.MaxSize.String())
+	}
+	if check.ContentType == "" {
+		return nil
+	}
+
+	contents, err := os.ReadFile(result.Path)
+	if err != nil {
+		return fmt.Errorf("unable to read artifact: %w", err)
+	}
+	if err = checkContentType(contents, check.ContentType); err != nil {
+		return err
+	}
+	result.Findings, err = summarizeFindings(contents, check.ContentType)
+	return err
+}
+
+// checkContentType returns an error if the contents are not of the given content type.
+func checkContentType(contents []byte, contentType v1beta1.ArtifactContentType) error {
+	switch contentType {
+	case v1beta1.ArtifactContentTypeJSON:
+		if !json.Valid(contents) {
+			return errors.New("artifact is not valid JSON")
+		}
+	case v1beta1.ArtifactContentTypeSARIF:
+		var log struct {
+			Version string            `json:"version"`
+			Runs    []json.RawMessage `json:"runs"`
+		}
+		if err := json.Unmarshal(contents, &log); err != nil {
+			return fmt.Errorf("artifact is not a valid SARIF log: %w", err)
+		}
+		if log.Version == "" || log.Runs == nil {
+			return errors.New("artifact is not a valid SARIF log: missing version or runs")
+		}
+	case v1beta1.ArtifactContentTypeCycloneDX:
+		var bom struct {
+			BOMFormat   string `json:"bomFormat"`
+			SpecVersion string `json:"specVersion"`
+		}
+		if err := json.Unmarshal(contents, &bom); err != nil {
+			return fmt.Errorf("artifact is not a valid CycloneDX BOM: %w", err)
+		}
+		if bom.BOMFormat != "CycloneDX" || bom.SpecVersion == "" {
+			return errors.New("artifact is not a valid CycloneDX BOM: missing bomFormat or specVersion")
+		}
+	default:
+		return fmt.Errorf("unknown content type %s", contentType)
+	}
+	return nil
+}
+
+// artifactPaths returns the artifact paths passed to an uploader,
+// which are all arguments after the last "--".
+func artifactPaths(args []string) []string {
+	if i := artifactSeparatorIndex(args); i >= 0 {
+		return args[i+1:]
+	}
+	return nil
+}
+
+// removeFailedArtifacts returns the arguments of an uploader
+// without the artifacts that failed their check.
+func removeFailedArtifacts(args []string, results []runtime.ArtifactResult) []string {
+	i := artifactSeparatorIndex(args)
+	if i < 0 {
+		return args
+	}
+	failed := func(arg string) bool {
+		return slices.ContainsFunc(results, func(r runtime.ArtifactResult) bool { return r.Path == arg && r.Error != "" })
+	}
+	return append(slices.Clone(args[:i+1]), slices.DeleteFunc(slices.Clone(args[i+1:]), failed)...)
+}
+
+// artifactSeparatorIndex returns the index of the last "--" in
+// the arguments of an uploader, or -1 if there is none.
+func artifactSeparatorIndex(args []string) int {
+	for i := len(args) - 1; i >= 0; i-- {
+		if args[i] == "--" {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteTerminationMessage writes the message as JSON to the termination message of the container.
+// If the message is larger than the kubelet allows, detail is removed from the artifacts with
+// [terminationMessageReductions] so that the message can still be parsed by the controller.
+func WriteTerminationMessage(message resources.UploaderTerminationMessage) error {
+	contents, err := marshalTerminationMessage(message)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(terminationMessagePath, contents, 0o644)
+}
+
+// marshalTerminationMessage marshals the message, applying [terminationMessageReductions]
+// to a copy of the artifacts until it fits in [maxTerminationMessageSize].
+func marshalTerminationMessage(message resources.UploaderTerminationMessage) ([]byte, error) {
+	artifacts := make([]v1beta1.PipelineArtifactStatus, len(message.Artifacts))
+	for i := range message.Artifacts {
+		message.Artifacts[i].DeepCopyInto(&artifacts[i])
+	}
+	message.Artifacts = artifacts
+
+	contents, err := json.Marshal(message)
+	for _, reduce := range terminationMessageReductions {
+		if err != nil || len(contents) <= maxTerminationMessageSize {
+			break
+		}
+		for i := range message.Artifacts {
+			reduce(&message.Artifacts[i])
+		}
+		contents, err = json.Marshal(message)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal termination message: %w", err)
+	}
+	if len(contents) > maxTerminationMessageSize {
+		return nil, fmt.Errorf("termination message is %d bytes, larger than the maximum of %d", len(contents), maxTerminationMessageSize)
+	}
+	return contents, nil
+}
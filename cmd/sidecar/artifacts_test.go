@@ -0,0 +1,175 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/resources"
+	"github.com/crashappsec/ocular/pkg/runtime"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCheckArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"empty.json":    "",
+		"report.json":   `{"ok": true}`,
+		"results.sarif": `{"version": "2.1.0", "runs": []}`,
+		"not.sarif":     `{"version": "2.1.0"}`,
+		"sbom.json":     `{"bomFormat": "CycloneDX", "specVersion": "1.5", "components": []}`,
+		"large.txt":     "0123456789",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(path.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		artifact  string
+		check     *v1beta1.ArtifactCheck
+		wantError bool
+	}{
+		{"missing without check", "missing.json", nil, true},
+		{"present without check", "empty.json", nil, false},
+		{"empty JSON", "empty.json", &v1beta1.ArtifactCheck{ContentType: v1beta1.ArtifactContentTypeJSON}, true},
+		{"valid JSON", "report.json", &v1beta1.ArtifactCheck{ContentType: v1beta1.ArtifactContentTypeJSON}, false},
+		{"valid SARIF", "results.sarif", &v1beta1.ArtifactCheck{ContentType: v1beta1.ArtifactContentTypeSARIF}, false},
+		{"SARIF without runs", "not.sarif", &v1beta1.ArtifactCheck{ContentType: v1beta1.ArtifactContentTypeSARIF}, true},
+		{"valid CycloneDX", "sbom.json", &v1beta1.ArtifactCheck{ContentType: v1beta1.ArtifactContentTypeCycloneDX}, false},
+		{"JSON is not CycloneDX", "report.json", &v1beta1.ArtifactCheck{ContentType: v1beta1.ArtifactContentTypeCycloneDX}, true},
+		{"within max size", "large.txt", &v1beta1.ArtifactCheck{MaxSize: new(resource.MustParse("10"))}, false},
+		{"exceeds max size", "large.txt", &v1beta1.ArtifactCheck{MaxSize: new(resource.MustParse("9"))}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := path.Join(dir, tt.artifact)
+			var checks []v1beta1.ArtifactCheck
+			if tt.check != nil {
+				check := *tt.check
+				check.Path = artifact
+				checks = append(checks, check)
+			}
+			results := CheckArtifacts([]string{artifact}, checks)
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			if got := results[0].Error != ""; got != tt.wantError {
+				t.Errorf("expected error %t, got result %+v", tt.wantError, results[0])
+			}
+		})
+	}
+}
+
+func TestRemoveFailedArtifacts(t *testing.T) {
+	args := []string{"upload", "--", "/mnt/results/a.json", "/mnt/results/b.json", "/mnt/metadata/c.json"}
+	results := []runtime.ArtifactResult{
+		{Path: "/mnt/results/a.json", Present: true},
+		{Path: "/mnt/results/b.json", Error: "artifact does not exist"},
+		{Path: "/mnt/metadata/c.json", Present: true},
+	}
+	want := []string{"upload", "--", "/mnt/results/a.json", "/mnt/metadata/c.json"}
+	if got := removeFailedArtifacts(args, results); !slices.Equal(got, want) {
+		t.Errorf("removeFailedArtifacts() = %v, want %v", got, want)
+	}
+	if got := removeFailedArtifacts([]string{"upload"}, results); !slices.Equal(got, []string{"upload"}) {
+		t.Errorf("expected arguments without artifacts to be unchanged, got %v", got)
+	}
+}
+
+func TestArtifactPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"no separator", []string{"upload", "-v"}, nil},
+		{"artifacts", []string{"upload", "--", "/mnt/results/a.json", "/mnt/metadata/b.json"}, []string{"/mnt/results/a.json", "/mnt/metadata/b.json"}},
+		{"separator in uploader args", []string{"upload", "--", "-v", "--", "/mnt/results/a.json"}, []string{"/mnt/results/a.json"}},
+		{"no artifacts", []string{"upload", "--"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := artifactPaths(tt.args); !slices.Equal(got, tt.want) {
+				t.Errorf("artifactPaths(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalTerminationMessage(t *testing.T) {
+	var artifacts []runtime.ArtifactResult
+	for i := range 10 {
+		artifacts = append(artifacts, runtime.ArtifactResult{
+			Path:  fmt.Sprintf("/mnt/results/%d.sarif", i),
+			Error: "artifact is not a valid SARIF log: " + strings.Repeat("x", 1024),
+		})
+	}
+	message := resources.UploaderTerminationMessage{Artifacts: artifacts}
+
+	contents, err := marshalTerminationMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contents) > maxTerminationMessageSize {
+		t.Fatalf("expected message to fit in %d bytes, got %d", maxTerminationMessageSize, len(contents))
+	}
+	var decoded resources.UploaderTerminationMessage
+	if err = json.Unmarshal(contents, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Artifacts) != len(artifacts) || decoded.Artifacts[0].Error == "" {
+		t.Errorf("expected every artifact to keep a shortened error, got %+v", decoded.Artifacts)
+	}
+	if len(artifacts[0].Error) <= maxTerminationMessageErrorLength {
+		t.Error("expected artifacts of the message to be unchanged")
+	}
+}
+
+func TestMarshalTerminationMessageFindings(t *testing.T) {
+	var artifacts []runtime.ArtifactResult
+	for i := range 12 {
+		byRule := make(map[string]int32)
+		for r := range maxFindingsRules {
+			byRule[fmt.Sprintf("a-long-rule-identifier-%02d", r)] = int32(r + 1)
+		}
+		artifacts = append(artifacts, runtime.ArtifactResult{
+			Path:    fmt.Sprintf("/mnt/results/%d.sarif", i),
+			Present: true,
+			Findings: &v1beta1.ArtifactFindings{
+				Total:      100,
+				BySeverity: map[string]int32{"high": 40, "low": 60},
+				ByRule:     byRule,
+			},
+		})
+	}
+
+	contents, err := marshalTerminationMessage(resources.UploaderTerminationMessage{Artifacts: artifacts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded resources.UploaderTerminationMessage
+	if err = json.Unmarshal(contents, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range decoded.Artifacts {
+		if a.Findings == nil || a.Findings.Total != 100 || a.Findings.ByRule != nil || len(a.Findings.BySeverity) != 2 {
+			t.Errorf("expected findings of %s to keep totals without rules, got %+v", a.Path, a.Findings)
+		}
+	}
+}
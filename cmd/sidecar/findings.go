@@ -0,0 +1,254 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+// maxFindingsRules is the maximum number of rules included in [v1beta1.ArtifactFindings.ByRule],
+// keeping the termination message of the uploader under its size limit.
+const maxFindingsRules = 10
+
+const (
+	severityCritical = "critical"
+	severityHigh     = "high"
+	severityMedium   = "medium"
+	severityLow      = "low"
+	severityInfo     = "info"
+	severityUnknown  = "unknown"
+)
+
+// summarizeFindings returns the summary of the findings in the contents of
+// an artifact, or nil if the content type does not contain findings.
+func summarizeFindings(contents []byte, contentType v1beta1.ArtifactContentType) (*v1beta1.ArtifactFindings, error) {
+	switch contentType {
+	case v1beta1.ArtifactContentTypeSARIF:
+		return summarizeSARIF(contents)
+	case v1beta1.ArtifactContentTypeCycloneDX:
+		return summarizeCycloneDX(contents)
+	default:
+		return nil, nil
+	}
+}
+
+type sarifProperties struct {
+	SecuritySeverity json.RawMessage `json:"security-severity"`
+}
+
+type sarifRule struct {
+	ID                   string          `json:"id"`
+	Properties           sarifProperties `json:"properties"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  *int            `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Kind       string          `json:"kind"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifLog struct {
+	Runs []struct {
+		Tool struct {
+			Driver struct {
+				Rules []sarifRule `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []sarifResult `json:"results"`
+	} `json:"runs"`
+}
+
+// summarizeSARIF counts the results of a SARIF log by severity and rule.
+func summarizeSARIF(contents []byte) (*v1beta1.ArtifactFindings, error) {
+	var log sarifLog
+	if err := json.Unmarshal(contents, &log); err != nil {
+		return nil, fmt.Errorf("unable to parse SARIF log: %w", err)
+	}
+
+	findings := &v1beta1.ArtifactFindings{BySeverity: map[string]int32{}}
+	byRule := map[string]int32{}
+	for _, run := range log.Runs {
+		rules := run.Tool.Driver.Rules
+		for _, result := range run.Results {
+			ruleIndex := -1
+			if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(rules) {
+				ruleIndex = *result.RuleIndex
+			} else if result.RuleID != "" {
+				ruleIndex = slices.IndexFunc(rules, func(r sarifRule) bool { return r.ID == result.RuleID })
+			}
+
+			ruleID, score, level := result.RuleID, parseSecuritySeverity(result.Properties.SecuritySeverity), result.Level
+			if ruleIndex >= 0 {
+				rule := rules[ruleIndex]
+				ruleID = cmp.Or(ruleID, rule.ID)
+				if score < 0 {
+					score = parseSecuritySeverity(rule.Properties.SecuritySeverity)
+				}
+				level = cmp.Or(level, rule.DefaultConfiguration.Level)
+			}
+
+			severity := sarifLevelSeverity(level, result.Kind)
+			if score >= 0 {
+				severity = scoreSeverity(score)
+			}
+			findings.Total++
+			findings.BySeverity[severity]++
+			if ruleID != "" {
+				byRule[ruleID]++
+			}
+		}
+	}
+	findings.ByRule = topRules(byRule, maxFindingsRules)
+	return findings, nil
+}
+
+// parseSecuritySeverity parses the security-severity property of a SARIF result or rule,
+// which is a CVSS score as a string. A negative value is returned if the property is not set
+// or invalid.
+func parseSecuritySeverity(value json.RawMessage) float64 {
+	if len(value) == 0 {
+		return -1
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		// some tools write the score as a number
+		s = string(value)
+	}
+	score, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || score < 0 {
+		return -1
+	}
+	return score
+}
+
+// scoreSeverity maps a CVSS score to its qualitative severity rating.
+func scoreSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return severityCritical
+	case score >= 7:
+		return severityHigh
+	case score >= 4:
+		return severityMedium
+	case score > 0:
+		return severityLow
+	default:
+		return severityInfo
+	}
+}
+
+// sarifLevelSeverity maps the level of a SARIF result to a severity.
+// Results without a level default to warning, unless they are not a failure.
+func sarifLevelSeverity(level, kind string) string {
+	if level == "" && kind != "" && kind != "fail" {
+		level = "none"
+	}
+	switch level {
+	case "error":
+		return severityHigh
+	case "warning", "":
+		return severityMedium
+	case "note":
+		return severityLow
+	case "none":
+		return severityInfo
+	default:
+		return severityUnknown
+	}
+}
+
+// topRules returns the n rules with the most results, ties broken by rule ID.
+func topRules(byRule map[string]int32, n int) map[string]int32 {
+	if len(byRule) == 0 {
+		return nil
+	}
+	ids := slices.SortedFunc(maps.Keys(byRule), func(a, b string) int {
+		return cmp.Or(cmp.Compare(byRule[b], byRule[a]), cmp.Compare(a, b))
+	})
+	top := make(map[string]int32, min(n, len(ids)))
+	for _, id := range ids[:min(n, len(ids))] {
+		top[id] = byRule[id]
+	}
+	return top
+}
+
+type cycloneDXComponent struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXBOM struct {
+	Components      []cycloneDXComponent `json:"components"`
+	Vulnerabilities []struct {
+		Ratings []struct {
+			Severity string `json:"severity"`
+		} `json:"ratings"`
+	} `json:"vulnerabilities"`
+}
+
+// cycloneDXSeverityRank is the rank of each CycloneDX severity,
+// used to find the highest rating of a vulnerability.
+var cycloneDXSeverityRank = map[string]int{
+	severityCritical: 5,
+	severityHigh:     4,
+	severityMedium:   3,
+	severityLow:      2,
+	severityInfo:     1,
+	"none":           1,
+}
+
+// summarizeCycloneDX counts the components of a CycloneDX BOM,
+// and its vulnerabilities by their highest rated severity.
+func summarizeCycloneDX(contents []byte) (*v1beta1.ArtifactFindings, error) {
+	var bom cycloneDXBOM
+	if err := json.Unmarshal(contents, &bom); err != nil {
+		return nil, fmt.Errorf("unable to parse CycloneDX BOM: %w", err)
+	}
+
+	findings := &v1beta1.ArtifactFindings{
+		BySeverity: map[string]int32{},
+		Components: countComponents(bom.Components),
+	}
+	for _, vuln := range bom.Vulnerabilities {
+		severity := severityUnknown
+		for _, rating := range vuln.Ratings {
+			s := strings.ToLower(rating.Severity)
+			if s == "none" {
+				s = severityInfo
+			}
+			if cycloneDXSeverityRank[s] > cycloneDXSeverityRank[severity] {
+				severity = s
+			}
+		}
+		findings.Total++
+		findings.BySeverity[severity]++
+	}
+	return findings, nil
+}
+
+// countComponents returns the number of components, including all nested components.
+func countComponents(components []cycloneDXComponent) int32 {
+	var count int32
+	for _, c := range components {
+		count += 1 + countComponents(c.Components)
+	}
+	return count
+}
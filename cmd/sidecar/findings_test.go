@@ -0,0 +1,112 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+func TestSummarizeSARIF(t *testing.T) {
+	contents := `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "scanner", "rules": [
+      {"id": "R1", "properties": {"security-severity": "9.8"}},
+      {"id": "R2", "defaultConfiguration": {"level": "note"}},
+      {"id": "R3"}
+    ]}},
+    "results": [
+      {"ruleId": "R1"},
+      {"ruleIndex": 0},
+      {"ruleId": "R2"},
+      {"ruleId": "R3", "level": "error"},
+      {"ruleId": "R3", "properties": {"security-severity": 5.5}},
+      {"ruleId": "R4"},
+      {"ruleId": "R4", "kind": "pass"}
+    ]
+  }]
+}`
+	findings, err := summarizeSARIF([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings.Total != 7 {
+		t.Errorf("expected 7 findings, got %d", findings.Total)
+	}
+	wantSeverity := map[string]int32{"critical": 2, "low": 1, "high": 1, "medium": 2, "info": 1}
+	if !maps.Equal(findings.BySeverity, wantSeverity) {
+		t.Errorf("expected severities %v, got %v", wantSeverity, findings.BySeverity)
+	}
+	wantRules := map[string]int32{"R1": 2, "R2": 1, "R3": 2, "R4": 2}
+	if !maps.Equal(findings.ByRule, wantRules) {
+		t.Errorf("expected rules %v, got %v", wantRules, findings.ByRule)
+	}
+}
+
+func TestSummarizeSARIFLimitsRules(t *testing.T) {
+	var results []string
+	for i := range maxFindingsRules + 5 {
+		for range i + 1 {
+			results = append(results, fmt.Sprintf(`{"ruleId": "R%02d"}`, i))
+		}
+	}
+	contents := `{"version": "2.1.0", "runs": [{"results": [` + strings.Join(results, ",") + `]}]}`
+	findings, err := summarizeSARIF([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings.ByRule) != maxFindingsRules {
+		t.Fatalf("expected %d rules, got %d", maxFindingsRules, len(findings.ByRule))
+	}
+	if _, ok := findings.ByRule["R00"]; ok {
+		t.Errorf("expected the rule with the fewest results to be dropped, got %v", findings.ByRule)
+	}
+}
+
+func TestSummarizeCycloneDX(t *testing.T) {
+	contents := `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"name": "a", "components": [{"name": "a1"}, {"name": "a2", "components": [{"name": "a2i"}]}]},
+    {"name": "b"}
+  ],
+  "vulnerabilities": [
+    {"id": "CVE-1", "ratings": [{"severity": "medium"}, {"severity": "Critical"}]},
+    {"id": "CVE-2", "ratings": [{"severity": "low"}]},
+    {"id": "CVE-3"}
+  ]
+}`
+	findings, err := summarizeFindings([]byte(contents), v1beta1.ArtifactContentTypeCycloneDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings.Components != 5 {
+		t.Errorf("expected 5 components, got %d", findings.Components)
+	}
+	if findings.Total != 3 {
+		t.Errorf("expected 3 findings, got %d", findings.Total)
+	}
+	wantSeverity := map[string]int32{"critical": 1, "low": 1, "unknown": 1}
+	if !maps.Equal(findings.BySeverity, wantSeverity) {
+		t.Errorf("expected severities %v, got %v", wantSeverity, findings.BySeverity)
+	}
+}
+
+func TestSummarizeFindingsIgnoresJSON(t *testing.T) {
+	findings, err := summarizeFindings([]byte(`{}`), v1beta1.ArtifactContentTypeJSON)
+	if err != nil || findings != nil {
+		t.Errorf("expected no findings for JSON artifacts, got %v, %v", findings, err)
+	}
+}
@@ -11,25 +11,38 @@
 // First the sidecar runs as an init container and copies itself to a shared volume.
 // then it will wrap the scanner containers to write their exit code to a file
 // with the name as the scanner container. Next the wrapped uploaders wait until
-// all scanners finish before starting.
+// all scanners finish before starting, writing a summary of the scanners for the
+// uploaders to read. The uploaders are skipped if the scanner failure policy
+// of the profile does not allow uploading after the scanners that failed.
+// The sidecar can also act as an uploader itself, uploading the artifacts
+// to an S3 compatible object store for uploaders that configure S3.
 package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/crashappsec/ocular/internal/process"
+	"github.com/crashappsec/ocular/internal/resources"
+	"github.com/crashappsec/ocular/pkg/runtime"
 	"golang.org/x/sync/errgroup"
 )
 
+// resultFileSuffix is appended to the mark path of a scanner
+// for the file containing its [runtime.ScannerResult].
+const resultFileSuffix = ".json"
+
 var (
 	version   = "unknown"
 	buildTime = "unknown"
@@ -83,25 +96,75 @@ func main() {
 			l.Error("unable to create process directory ", slog.Any("error", err))
 		}
 	case "await-scanners":
-		cmd, err := process.BuildUserCommand(cancelCtx, userCmd)
+		cmdCtx, cancelCmd := context.WithCancelCause(cancelCtx)
+		cmd, err := process.BuildUserCommand(cmdCtx, userCmd)
 		if err != nil {
 			l.Error("unable to parse user command", slog.Any("error", err))
 			os.Exit(1)
 		}
-		awaitScannersHook := AwaitScans(strings.Split(os.Getenv(v1beta1.EnvVarScanContainerNames), ","))
-		exitCode, err := process.HookCommand(cancelCtx, cmd, awaitScannersHook, nil)
+		scanners := strings.Split(os.Getenv(v1beta1.EnvVarScanContainerNames), ",")
+		checks, err := ArtifactChecksFromEnvironment()
+		if err != nil {
+			l.Error("unable to parse artifact checks", slog.Any("error", err))
+			os.Exit(1)
+		}
+		if err = AwaitScans(scanners, checks)(cancelCtx, cmd); err != nil {
+			l.Error("unable to await scanners", slog.Any("error", err))
+		}
+		failed, err := FailedScanners(scanners)
+		if err != nil {
+			l.Error("unable to determine failed scanners", slog.Any("error", err))
+			os.Exit(1)
+		}
+		policy := v1beta1.ScannerFailurePolicy(os.Getenv(v1beta1.EnvVarScannerFailurePolicy))
+		if !resources.ScannerFailuresAllowUpload(policy, len(failed), len(scanners)) {
+			l.Info("skipping uploader, scanners failed", slog.Any("failedScanners", failed), slog.String("failurePolicy", string(policy)))
+			os.Exit(0)
+		}
+		cmd.Env = append(cmd.Env, v1beta1.EnvVarFailedScanners+"="+strings.Join(failed, ","))
+		// the uploader deadline starts once the scanners have completed
+		if err = StartTimeout(cancelCmd); err != nil {
+			l.Error("unable to start uploader timeout", slog.Any("error", err))
+			os.Exit(1)
+		}
+		exitCode, err := process.HookCommand(cmdCtx, cmd, nil, nil)
+		if TimedOut(cmdCtx) {
+			l.Error("uploader exceeded its deadline", slog.String("timeout", os.Getenv(v1beta1.EnvVarTimeoutSeconds)))
+			os.Exit(resources.TimedOutExitCode)
+		}
 		if err != nil {
 			l.Error("unable to execute scanner", slog.Any("error", err))
 			os.Exit(1)
 		}
 		os.Exit(exitCode)
+	case "upload-s3":
+		client, err := S3ClientFromEnvironment()
+		if err != nil {
+			l.Error("unable to configure s3 client", slog.Any("error", err))
+			os.Exit(1)
+		}
+		prefix := S3KeyPrefixFromEnvironment()
+		l.Info("uploading artifacts to s3", slog.String("bucket", client.Bucket), slog.String("prefix", prefix))
+		if err = UploadS3(cancelCtx, client, prefix, os.Getenv(v1beta1.EnvVarResultsDir), artifactPaths(userCmd)); err != nil {
+			l.Error("unable to upload artifacts to s3", slog.Any("error", err))
+			os.Exit(1)
+		}
 	case "scanner":
-		cmd, err := process.BuildUserCommand(cancelCtx, userCmd)
+		cmdCtx, cancelCmd := context.WithCancelCause(cancelCtx)
+		cmd, err := process.BuildUserCommand(cmdCtx, userCmd)
 		if err != nil {
 			l.Error("unable to parse user command", slog.Any("error", err))
 			os.Exit(1)
 		}
-		exitCode, err := process.HookCommand(cancelCtx, cmd, nil, ScanCompleteHook(os.Getenv(v1beta1.EnvVarContainerName)))
+		if err = StartTimeout(cancelCmd); err != nil {
+			l.Error("unable to start scanner timeout", slog.Any("error", err))
+			os.Exit(1)
+		}
+		exitCode, err := process.HookCommand(cmdCtx, cmd, nil, ScanCompleteHook(os.Getenv(v1beta1.EnvVarContainerName), time.Now()))
+		if TimedOut(cmdCtx) {
+			l.Error("scanner exceeded its deadline", slog.String("timeout", os.Getenv(v1beta1.EnvVarTimeoutSeconds)))
+			os.Exit(resources.TimedOutExitCode)
+		}
 		if err != nil {
 			l.Error("unable to execute scanner", slog.Any("error", err))
 			os.Exit(1)
@@ -113,15 +176,21 @@ func main() {
 	}
 }
 
-func AwaitScans(scanners []string) process.Hook {
-	return func(ctx context.Context, _ *exec.Cmd) error {
-		g, ctx := errgroup.WithContext(ctx)
+// AwaitScans returns a hook that waits for each of the scanners to complete, then checks the
+// artifacts passed to the command. Artifacts that fail their check are removed from the arguments
+// of the command, and the results are written as the termination message of the container.
+// Finally the [runtime.ArtifactManifest], built once for the pod by [EnsureArtifactManifest], and the
+// [runtime.ScanSummary] are written to the paths in [v1beta1.EnvVarArtifactManifestPath] and
+// [v1beta1.EnvVarScanSummaryPath].
+func AwaitScans(scanners []string, checks []v1beta1.ArtifactCheck) process.Hook {
+	return func(ctx context.Context, cmd *exec.Cmd) error {
+		g, gCtx := errgroup.WithContext(ctx)
 		for _, scanner := range scanners {
 			l := slog.With(slog.String("scanner", scanner))
 			l.Info("awating scanner")
 			g.Go(func() error {
 				for {
-					complete, err := IsScannerComplete(ctx, scanner)
+					complete, err := IsScannerComplete(gCtx, scanner)
 					if err != nil {
 						l.Error("unable to check for scanner completion", slog.Any("error", err))
 						return err
@@ -136,10 +205,109 @@ func AwaitScans(scanners []string) process.Hook {
 				}
 			})
 		}
-		return g.Wait()
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		artifacts := CheckArtifacts(artifactPaths(cmd.Args), checks)
+		cmd.Args = removeFailedArtifacts(cmd.Args, artifacts)
+		if err := WriteTerminationMessage(resources.UploaderTerminationMessage{Artifacts: artifacts}); err != nil {
+			// the message is only used to report the artifacts in the pipeline status
+			slog.Warn("unable to write termination message", slog.Any("error", err))
+		}
+
+		if manifestPath := os.Getenv(v1beta1.EnvVarArtifactManifestPath); manifestPath != "" {
+			if err := EnsureArtifactManifest(ctx, manifestPath, scanners, artifacts); err != nil {
+				return err
+			}
+		}
+
+		summaryPath := os.Getenv(v1beta1.EnvVarScanSummaryPath)
+		if summaryPath == "" {
+			return nil
+		}
+		return WriteScanSummary(summaryPath, scanners, artifacts)
 	}
 }
 
+// WriteScanSummary writes the [runtime.ScanSummary] of the scanners and artifacts as JSON to summaryPath.
+func WriteScanSummary(summaryPath string, scanners []string, artifacts []runtime.ArtifactResult) error {
+	summary := runtime.ScanSummary{
+		Scanners:  make([]runtime.ScannerResult, 0, len(scanners)),
+		Artifacts: artifacts,
+	}
+	for _, scanner := range scanners {
+		result, err := readScannerResult(scanner)
+		if err != nil {
+			return err
+		}
+		summary.Scanners = append(summary.Scanners, result)
+	}
+	contents, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("unable to marshal scan summary: %w", err)
+	}
+	if err = writeFileAtomic(summaryPath, contents); err != nil {
+		return fmt.Errorf("unable to write scan summary to '%s': %w", summaryPath, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes contents to a temporary file in the directory of name and renames it to name.
+// Every uploader writes the same files to the shared process directory, so a reader never sees a
+// file that another uploader has only partially written.
+func writeFileAtomic(name string, contents []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(contents)
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// readScannerResult reads the result written by [ScanCompleteHook] for the scanner.
+// If the scanner has not completed, the exit code of the result is -1.
+func readScannerResult(scanner string) (runtime.ScannerResult, error) {
+	markPath := path.Join(os.Getenv(v1beta1.EnvVarProcessDir), scanner)
+	result := runtime.ScannerResult{Name: scanner, ExitCode: -1}
+
+	contents, err := os.ReadFile(markPath + resultFileSuffix)
+	if err == nil {
+		if err = json.Unmarshal(contents, &result); err != nil {
+			return result, fmt.Errorf("invalid result for scanner %s: %w", scanner, err)
+		}
+		return result, nil
+	} else if !os.IsNotExist(err) {
+		return result, fmt.Errorf("unable to read result for scanner %s: %w", scanner, err)
+	}
+
+	// fallback to the exit code in the mark file
+	contents, err = os.ReadFile(markPath)
+	if os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return result, fmt.Errorf("unable to read mark path '%s' for scanner %s: %w", markPath, scanner, err)
+	}
+	result.ExitCode, err = strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return result, fmt.Errorf("invalid exit code '%s' for scanner %s: %w", contents, scanner, err)
+	}
+	return result, nil
+}
+
 func IsScannerComplete(_ context.Context, scanner string) (bool, error) {
 	markPath := path.Join(os.Getenv(v1beta1.EnvVarProcessDir), scanner)
 	_, err := os.Stat(markPath)
@@ -152,11 +320,74 @@ func IsScannerComplete(_ context.Context, scanner string) (bool, error) {
 	return true, nil
 }
 
-func ScanCompleteHook(scanner string) process.Hook {
+// errTimedOut is the cause of the cancellation of a
+// command context once the command exceeded its deadline.
+var errTimedOut = errors.New("deadline exceeded")
+
+// StartTimeout cancels the command context with [errTimedOut] once the deadline
+// in [v1beta1.EnvVarTimeoutSeconds] has passed, which signals the command built by
+// [process.BuildUserCommand] to stop. No deadline is started if the variable is not set.
+func StartTimeout(cancel context.CancelCauseFunc) error {
+	value := os.Getenv(v1beta1.EnvVarTimeoutSeconds)
+	if value == "" {
+		return nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return fmt.Errorf("invalid timeout '%s' in %s", value, v1beta1.EnvVarTimeoutSeconds)
+	}
+	time.AfterFunc(time.Duration(seconds)*time.Second, func() { cancel(errTimedOut) })
+	return nil
+}
+
+// TimedOut returns true if the command context was cancelled by [StartTimeout].
+func TimedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errTimedOut)
+}
+
+// FailedScanners returns the scanners which exited with a non-zero exit code,
+// or have not completed (e.g. the await was cancelled).
+func FailedScanners(scanners []string) ([]string, error) {
+	var failed []string
+	for _, scanner := range scanners {
+		result, err := readScannerResult(scanner)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Succeeded() {
+			failed = append(failed, scanner)
+		}
+	}
+	return failed, nil
+}
+
+// ScanCompleteHook returns a hook that writes the [runtime.ScannerResult] of the scanner,
+// followed by its exit code to the mark file awaited by [AwaitScans].
+func ScanCompleteHook(scanner string, startedAt time.Time) process.Hook {
 	return func(ctx context.Context, cmd *exec.Cmd) error {
 		markPath := path.Join(os.Getenv(v1beta1.EnvVarProcessDir), scanner)
 
 		exitcode := cmd.ProcessState.ExitCode()
+		if TimedOut(ctx) {
+			exitcode = resources.TimedOutExitCode
+		}
+		finishedAt := time.Now()
+		result, err := json.Marshal(runtime.ScannerResult{
+			Name:            scanner,
+			ExitCode:        exitcode,
+			StartedAt:       &startedAt,
+			FinishedAt:      &finishedAt,
+			DurationSeconds: finishedAt.Sub(startedAt).Seconds(),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to marshal result for scanner %s: %w", scanner, err)
+		}
+		// the result is written before the mark file, so that it
+		// is always present once the scanner is seen as complete
+		if err = os.WriteFile(markPath+resultFileSuffix, result, 0o644); err != nil {
+			return fmt.Errorf("unable to write result for scanner %s: %w", scanner, err)
+		}
+
 		f, err := os.Create(markPath)
 		if err != nil {
 			return fmt.Errorf("unable to create mark path '%s' for scanner %s: %w", markPath, scanner, err)
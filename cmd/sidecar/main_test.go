@@ -0,0 +1,142 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/process"
+	"github.com/crashappsec/ocular/internal/resources"
+	"github.com/crashappsec/ocular/pkg/runtime"
+)
+
+func TestWriteScanSummary(t *testing.T) {
+	procDir := t.TempDir()
+	t.Setenv(v1beta1.EnvVarProcessDir, procDir)
+
+	for scanner, command := range map[string]string{
+		"scanner-passing": "true",
+		"scanner-failing": "false",
+	} {
+		cmd := exec.Command(command)
+		_ = cmd.Run()
+		if err := ScanCompleteHook(scanner, time.Now().Add(-time.Second))(context.Background(), cmd); err != nil {
+			t.Fatalf("unable to complete scanner %s: %v", scanner, err)
+		}
+	}
+	// a scanner from an older sidecar which only wrote its exit code
+	if err := os.WriteFile(path.Join(procDir, "scanner-legacy"), []byte("2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := path.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(artifact, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := path.Join(t.TempDir(), "missing.json")
+
+	summaryPath := path.Join(procDir, "summary.json")
+	scanners := []string{"scanner-passing", "scanner-failing", "scanner-legacy"}
+	if err := WriteScanSummary(summaryPath, scanners, CheckArtifacts([]string{artifact, missing}, nil)); err != nil {
+		t.Fatalf("unable to write scan summary: %v", err)
+	}
+
+	summary, err := runtime.ReadScanSummary(summaryPath)
+	if err != nil {
+		t.Fatalf("unable to read scan summary: %v", err)
+	}
+	exitCodes := map[string]int{"scanner-passing": 0, "scanner-failing": 1, "scanner-legacy": 2}
+	if len(summary.Scanners) != len(exitCodes) {
+		t.Fatalf("expected %d scanners, got %d", len(exitCodes), len(summary.Scanners))
+	}
+	for _, s := range summary.Scanners {
+		if s.ExitCode != exitCodes[s.Name] {
+			t.Errorf("expected exit code %d for %s, got %d", exitCodes[s.Name], s.Name, s.ExitCode)
+		}
+		if s.Name == "scanner-legacy" {
+			continue
+		}
+		if s.StartedAt == nil || s.FinishedAt == nil || s.DurationSeconds < 1 {
+			t.Errorf("expected timing for %s, got %+v", s.Name, s)
+		}
+	}
+	expectedArtifacts := []runtime.ArtifactResult{
+		{Path: artifact, Present: true, SizeBytes: 2},
+		{Path: missing, Present: false, Error: "artifact does not exist"},
+	}
+	if !slices.Equal(summary.Artifacts, expectedArtifacts) {
+		t.Errorf("expected artifacts %v, got %v", expectedArtifacts, summary.Artifacts)
+	}
+
+	// every uploader in the pod writes the summary, which must always be readable
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if err := WriteScanSummary(summaryPath, scanners, summary.Artifacts); err != nil {
+				t.Errorf("unable to write scan summary concurrently: %v", err)
+			}
+			if _, err := runtime.ReadScanSummary(summaryPath); err != nil {
+				t.Errorf("unable to read scan summary while it is written: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if tmps, _ := filepath.Glob(summaryPath + ".*.tmp"); len(tmps) != 0 {
+		t.Errorf("expected temporary files to be renamed, found %v", tmps)
+	}
+
+	failed, err := FailedScanners(scanners)
+	if err != nil {
+		t.Fatalf("unable to determine failed scanners: %v", err)
+	}
+	if !slices.Equal(failed, []string{"scanner-failing", "scanner-legacy"}) {
+		t.Errorf("unexpected failed scanners %v", failed)
+	}
+}
+
+func TestStartTimeout(t *testing.T) {
+	procDir := t.TempDir()
+	t.Setenv(v1beta1.EnvVarProcessDir, procDir)
+	t.Setenv(v1beta1.EnvVarTimeoutSeconds, "1")
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	cmd, err := process.BuildUserCommand(ctx, []string{"sleep", "30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = StartTimeout(cancel); err != nil {
+		t.Fatalf("unable to start timeout: %v", err)
+	}
+	start := time.Now()
+	_, _ = process.HookCommand(ctx, cmd, nil, ScanCompleteHook("scanner-slow", start))
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the scanner to be stopped after its deadline, ran for %s", elapsed)
+	}
+	if !TimedOut(ctx) {
+		t.Errorf("expected the scanner context to be timed out")
+	}
+
+	result, err := readScannerResult("scanner-slow")
+	if err != nil {
+		t.Fatalf("unable to read scanner result: %v", err)
+	}
+	if result.ExitCode != resources.TimedOutExitCode {
+		t.Errorf("expected exit code %d, got %d", resources.TimedOutExitCode, result.ExitCode)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+)
+
+// EnsureArtifactManifest writes the artifact manifest with [WriteArtifactManifest] once for the pod.
+// Every uploader of a pod checks the same artifacts, so the first uploader to lock the manifest
+// builds it while the others wait for it to be written. The process directory is kept between
+// the pods of a pipeline, so the lock and the marker written once the manifest is built are
+// named after the pod. If the uploader holding the lock fails, the next uploader takes it over.
+func EnsureArtifactManifest(ctx context.Context, manifestPath string, scanners []string, artifacts []runtime.ArtifactResult) error {
+	marker := manifestPath
+	if pod := os.Getenv(v1beta1.EnvVarPodName); pod != "" {
+		marker += "." + pod
+	}
+	lockPath, donePath := marker+".lock", marker+".done"
+	for {
+		if _, err := os.Stat(donePath); err == nil {
+			return nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("unable to check for artifact manifest '%s': %w", manifestPath, err)
+		}
+
+		lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = lock.Close()
+			if err = WriteArtifactManifest(manifestPath, scanners, artifacts); err != nil {
+				_ = os.Remove(lockPath)
+				return err
+			}
+			done, err := os.Create(donePath)
+			if err != nil {
+				_ = os.Remove(lockPath)
+				return fmt.Errorf("unable to mark artifact manifest '%s' as written: %w", manifestPath, err)
+			}
+			return done.Close()
+		} else if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("unable to lock artifact manifest '%s': %w", manifestPath, err)
+		}
+
+		slog.Info("artifact manifest is being written by another uploader, sleeping before checking again")
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// WriteArtifactManifest writes the [runtime.ArtifactManifest] of the artifacts that passed
+// their check as JSON to manifestPath. Artifacts within the metadata directory are listed
+// as [runtime.ArtifactKindMetadata], and the results of the scanners are used to determine
+// which scanner produced each of the other artifacts.
+func WriteArtifactManifest(manifestPath string, scanners []string, artifacts []runtime.ArtifactResult) error {
+	results := make([]runtime.ScannerResult, 0, len(scanners))
+	for _, scanner := range scanners {
+		result, err := readScannerResult(scanner)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	manifest := runtime.ArtifactManifest{
+		Target: v1beta1.Target{
+			Identifier: os.Getenv(v1beta1.EnvVarTargetIdentifier),
+			Version:    os.Getenv(v1beta1.EnvVarTargetVersion),
+		},
+		Artifacts: []runtime.ArtifactManifestEntry{},
+	}
+	metadataDir := os.Getenv(v1beta1.EnvVarMetadataDir)
+	for _, artifact := range artifacts {
+		if artifact.Error != "" {
+			continue
+		}
+		err := filepath.WalkDir(artifact.Path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			entry, err := manifestEntry(file, metadataDir, results)
+			if err != nil {
+				return err
+			}
+			manifest.Artifacts = append(manifest.Artifacts, entry)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("unable to add artifact %s to manifest: %w", artifact.Path, err)
+		}
+	}
+
+	contents, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("unable to marshal artifact manifest: %w", err)
+	}
+	if err = writeFileAtomic(manifestPath, contents); err != nil {
+		return fmt.Errorf("unable to write artifact manifest to '%s': %w", manifestPath, err)
+	}
+	return nil
+}
+
+// manifestEntry returns the [runtime.ArtifactManifestEntry] for the file.
+func manifestEntry(file, metadataDir string, scanners []runtime.ScannerResult) (runtime.ArtifactManifestEntry, error) {
+	entry := runtime.ArtifactManifestEntry{Path: file, Kind: runtime.ArtifactKindArtifact}
+	if metadataDir != "" && strings.HasPrefix(file, filepath.Clean(metadataDir)+string(filepath.Separator)) {
+		entry.Kind = runtime.ArtifactKindMetadata
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return entry, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return entry, err
+	}
+	hash := sha256.New()
+	if entry.SizeBytes, err = io.Copy(hash, f); err != nil {
+		return entry, err
+	}
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if entry.Kind == runtime.ArtifactKindArtifact {
+		entry.Scanner = producingScanner(info.ModTime(), scanners)
+	}
+	return entry, nil
+}
+
+// producingScanner returns the name of the only scanner that was running at modTime,
+// or an empty string if no scanner or more than one scanner was running.
+func producingScanner(modTime time.Time, scanners []runtime.ScannerResult) string {
+	var producer string
+	for _, s := range scanners {
+		if s.StartedAt == nil || s.FinishedAt == nil || modTime.Before(*s.StartedAt) || modTime.After(*s.FinishedAt) {
+			continue
+		}
+		if producer != "" {
+			return ""
+		}
+		producer = s.Name
+	}
+	return producer
+}
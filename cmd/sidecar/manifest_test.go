@@ -0,0 +1,153 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+)
+
+func TestWriteArtifactManifest(t *testing.T) {
+	procDir, resultsDir, metadataDir := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv(v1beta1.EnvVarProcessDir, procDir)
+	t.Setenv(v1beta1.EnvVarMetadataDir, metadataDir)
+	t.Setenv(v1beta1.EnvVarTargetIdentifier, "https://github.com/crashappsec/ocular")
+	t.Setenv(v1beta1.EnvVarTargetVersion, "main")
+
+	// scanner-a runs alone for the first minute, then overlaps with scanner-b
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	for _, result := range []runtime.ScannerResult{
+		{Name: "scanner-a", StartedAt: new(at(0)), FinishedAt: new(at(3))},
+		{Name: "scanner-b", StartedAt: new(at(2)), FinishedAt: new(at(5))},
+	} {
+		contents, _ := json.Marshal(result)
+		if err := os.WriteFile(path.Join(procDir, result.Name+resultFileSuffix), contents, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := []struct {
+		path     string
+		contents string
+		modified time.Time
+	}{
+		{path.Join(resultsDir, "a.json"), "a", at(1)},
+		{path.Join(resultsDir, "both", "ab.json"), "ab", at(2)},
+		{path.Join(resultsDir, "both", "b.json"), "b", at(4)},
+		{path.Join(metadataDir, "commit.json"), "{}", at(1)},
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(path.Dir(f.path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f.path, []byte(f.contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(f.path, f.modified, f.modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts := CheckArtifacts([]string{
+		path.Join(resultsDir, "a.json"),
+		path.Join(resultsDir, "both"),
+		path.Join(resultsDir, "missing.json"),
+		path.Join(metadataDir, "commit.json"),
+	}, nil)
+	manifestPath := path.Join(procDir, "manifest.json")
+	if err := WriteArtifactManifest(manifestPath, []string{"scanner-a", "scanner-b"}, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(v1beta1.EnvVarArtifactManifestPath, manifestPath)
+	manifest, err := runtime.GetArtifactManifestFromEnvironment()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Target.Identifier != "https://github.com/crashappsec/ocular" || manifest.Target.Version != "main" {
+		t.Errorf("unexpected target %+v", manifest.Target)
+	}
+	want := []runtime.ArtifactManifestEntry{
+		{Path: files[0].path, Kind: runtime.ArtifactKindArtifact, Scanner: "scanner-a", SHA256: sha256Hex([]byte("a")), SizeBytes: 1},
+		{Path: files[1].path, Kind: runtime.ArtifactKindArtifact, SHA256: sha256Hex([]byte("ab")), SizeBytes: 2},
+		{Path: files[2].path, Kind: runtime.ArtifactKindArtifact, Scanner: "scanner-b", SHA256: sha256Hex([]byte("b")), SizeBytes: 1},
+		{Path: files[3].path, Kind: runtime.ArtifactKindMetadata, SHA256: sha256Hex([]byte("{}")), SizeBytes: 2},
+	}
+	if len(manifest.Artifacts) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), manifest.Artifacts)
+	}
+	for i := range want {
+		if manifest.Artifacts[i] != want[i] {
+			t.Errorf("expected entry %+v, got %+v", want[i], manifest.Artifacts[i])
+		}
+	}
+}
+
+func TestEnsureArtifactManifest(t *testing.T) {
+	procDir, resultsDir := t.TempDir(), t.TempDir()
+	t.Setenv(v1beta1.EnvVarProcessDir, procDir)
+	t.Setenv(v1beta1.EnvVarPodName, "pipeline-upload")
+
+	artifact := path.Join(resultsDir, "results.json")
+	if err := os.WriteFile(artifact, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	artifacts := CheckArtifacts([]string{artifact}, nil)
+	manifestPath := path.Join(procDir, "manifest.json")
+	t.Setenv(v1beta1.EnvVarArtifactManifestPath, manifestPath)
+
+	// every uploader of the pod ensures the manifest at the same time
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			if err := EnsureArtifactManifest(t.Context(), manifestPath, nil, artifacts); err != nil {
+				t.Errorf("unable to ensure artifact manifest: %v", err)
+			}
+			if _, err := runtime.GetArtifactManifestFromEnvironment(); err != nil {
+				t.Errorf("unable to read artifact manifest once ensured: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	// the manifest is not built again for the same pod
+	if err := os.WriteFile(artifact, []byte("{\"changed\":true}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureArtifactManifest(t.Context(), manifestPath, nil, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := runtime.GetArtifactManifestFromEnvironment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Artifacts) != 1 || manifest.Artifacts[0].SHA256 != sha256Hex([]byte("{}")) {
+		t.Errorf("expected the manifest of the first uploader, got %+v", manifest.Artifacts)
+	}
+
+	// a later pod of the pipeline builds its own manifest
+	t.Setenv(v1beta1.EnvVarPodName, "pipeline-upload-reupload-1")
+	if err = EnsureArtifactManifest(t.Context(), manifestPath, nil, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	if manifest, err = runtime.GetArtifactManifestFromEnvironment(); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Artifacts) != 1 || manifest.Artifacts[0].SHA256 != sha256Hex([]byte("{\"changed\":true}")) {
+		t.Errorf("expected the manifest of the later pod, got %+v", manifest.Artifacts)
+	}
+}
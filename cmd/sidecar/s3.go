@@ -0,0 +1,262 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+const (
+	// s3DefaultRegion is the region used to sign requests when none is configured.
+	s3DefaultRegion = "us-east-1"
+	// s3SigningAlgorithm is the algorithm of AWS signature version 4.
+	s3SigningAlgorithm = "AWS4-HMAC-SHA256"
+	// s3TimeFormat is the format of the x-amz-date header.
+	s3TimeFormat = "20060102T150405Z"
+)
+
+// S3Client uploads objects to a bucket of an S3 compatible object store,
+// signing each request with AWS signature version 4.
+type S3Client struct {
+	Endpoint        *url.URL
+	Region          string
+	Bucket          string
+	ForcePathStyle  bool
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	HTTPClient      *http.Client
+}
+
+// S3ClientFromEnvironment returns an [S3Client] configured from the environment
+// variables set by the controller for an uploader with [v1beta1.UploaderSpec.S3] set.
+func S3ClientFromEnvironment() (*S3Client, error) {
+	c := &S3Client{
+		Region:          cmp.Or(os.Getenv(v1beta1.EnvVarS3Region), s3DefaultRegion),
+		Bucket:          os.Getenv(v1beta1.EnvVarS3Bucket),
+		AccessKeyID:     os.Getenv(v1beta1.EnvVarAWSAccessKeyID),
+		SecretAccessKey: os.Getenv(v1beta1.EnvVarAWSSecretAccessKey),
+		SessionToken:    os.Getenv(v1beta1.EnvVarAWSSessionToken),
+		HTTPClient:      http.DefaultClient,
+	}
+	if c.Bucket == "" {
+		return nil, fmt.Errorf("%s must be set", v1beta1.EnvVarS3Bucket)
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%s and %s must be set", v1beta1.EnvVarAWSAccessKeyID, v1beta1.EnvVarAWSSecretAccessKey)
+	}
+	if forcePathStyle := os.Getenv(v1beta1.EnvVarS3ForcePathStyle); forcePathStyle != "" {
+		var err error
+		if c.ForcePathStyle, err = strconv.ParseBool(forcePathStyle); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", v1beta1.EnvVarS3ForcePathStyle, err)
+		}
+	}
+	endpoint := cmp.Or(os.Getenv(v1beta1.EnvVarS3Endpoint), "https://s3."+c.Region+".amazonaws.com")
+	var err error
+	if c.Endpoint, err = url.Parse(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", v1beta1.EnvVarS3Endpoint, err)
+	}
+	return c, nil
+}
+
+// objectURL returns the URL of the object with the given key.
+func (c *S3Client) objectURL(key string) *url.URL {
+	u := *c.Endpoint
+	objectPath := "/" + key
+	if c.ForcePathStyle {
+		objectPath = "/" + c.Bucket + objectPath
+	} else {
+		u.Host = c.Bucket + "." + u.Host
+	}
+	u.Path = path.Join(strings.TrimSuffix(u.Path, "/"), objectPath)
+	u.RawPath = s3EscapePath(u.Path)
+	return &u
+}
+
+// PutObject uploads the contents of body as the object with the given key.
+func (c *S3Client) PutObject(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	hash := sha256.New()
+	size, err := io.Copy(hash, body)
+	if err != nil {
+		return fmt.Errorf("unable to hash object: %w", err)
+	}
+	if _, err = body.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to read object: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectURL(key).String(), io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.sign(req, hex.EncodeToString(hash.Sum(nil)), time.Now())
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to upload object %s: %w", key, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unable to upload object %s: %s: %s", key, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// sign adds the headers for AWS signature version 4 to the request,
+// signing the host and all headers set on the request.
+func (c *S3Client) sign(req *http.Request, payloadHash string, t time.Time) {
+	t = t.UTC()
+	req.Header.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+
+	headers := map[string]string{"host": cmp.Or(req.Host, req.URL.Host)}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := slices.Sorted(maps.Keys(headers))
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := t.Format("20060102")
+	scope := date + "/" + c.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		s3SigningAlgorithm,
+		t.Format(s3TimeFormat),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	for _, part := range []string{c.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SigningAlgorithm, c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3EscapePath escapes each segment of an object path as required by AWS signature version 4,
+// where every byte except unreserved characters is percent encoded.
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch ch := p[i]; {
+		case ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9',
+			ch == '-', ch == '.', ch == '_', ch == '~', ch == '/':
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+// S3KeyPrefixFromEnvironment expands the key template from [v1beta1.EnvVarS3KeyTemplate],
+// or [v1beta1.S3UploaderDefaultKeyTemplate] if not set, with the values of the pipeline.
+func S3KeyPrefixFromEnvironment() string {
+	target := os.Getenv(v1beta1.EnvVarTargetIdentifier)
+	if _, rest, ok := strings.Cut(target, "://"); ok {
+		target = rest
+	}
+	replacer := strings.NewReplacer(
+		"{namespace}", os.Getenv(v1beta1.EnvVarNamespaceName),
+		"{profile}", os.Getenv(v1beta1.EnvVarProfileName),
+		"{downloader}", os.Getenv(v1beta1.EnvVarDownloaderName),
+		"{uploader}", os.Getenv(v1beta1.EnvVarUploaderName),
+		"{pipeline}", os.Getenv(v1beta1.EnvVarPipelineName),
+		"{target}", target,
+		"{version}", os.Getenv(v1beta1.EnvVarTargetVersion),
+	)
+	template := cmp.Or(os.Getenv(v1beta1.EnvVarS3KeyTemplate), v1beta1.S3UploaderDefaultKeyTemplate)
+	segments := strings.Split(replacer.Replace(template), "/")
+	return strings.Join(slices.DeleteFunc(segments, func(s string) bool { return s == "" }), "/")
+}
+
+// UploadS3 uploads each of the artifacts, and every file within artifacts that are
+// directories, to the bucket. The key of each object is the prefix followed by the
+// path of the file relative to the parent of baseDir.
+func UploadS3(ctx context.Context, client *S3Client, prefix, baseDir string, artifacts []string) error {
+	root := path.Dir(path.Clean(baseDir))
+	for _, artifact := range artifacts {
+		err := filepath.WalkDir(artifact, func(file string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			name, err := filepath.Rel(root, file)
+			if err != nil || name == ".." || strings.HasPrefix(name, "../") {
+				name = strings.TrimPrefix(file, "/")
+			}
+			key := strings.TrimPrefix(prefix+"/"+filepath.ToSlash(name), "/")
+
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = f.Close() }()
+			slog.Info("uploading artifact", slog.String("file", file), slog.String("key", key))
+			return client.PutObject(ctx, key, f, mime.TypeByExtension(filepath.Ext(file)))
+		})
+		if err != nil {
+			return fmt.Errorf("unable to upload artifact %s: %w", artifact, err)
+		}
+	}
+	return nil
+}
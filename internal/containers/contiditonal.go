@@ -48,7 +48,7 @@ func FilterConditionalContainers(cs []v1beta1.ConditionalContainer, definitions
 
 	for _, c := range cs {
 		if shouldInclude(c.IncludeIf, setParams) {
-			result = append(result, c.Container)
+			result = append(result, ApplyOptionsTo(c.Container, WithTimeout(c.ActiveDeadlineSeconds)))
 		}
 	}
 	return result
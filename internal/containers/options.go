@@ -9,6 +9,8 @@
 package containers
 
 import (
+	"strconv"
+
 	"github.com/crashappsec/ocular/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -27,6 +29,20 @@ func WithAdditionalArgs(args ...string) Option {
 	}
 }
 
+// WithTimeout sets the deadline enforced by the sidecar for the container,
+// if seconds is not nil.
+func WithTimeout(seconds *int64) Option {
+	return func(c *corev1.Container) {
+		if seconds == nil {
+			return
+		}
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  v1beta1.EnvVarTimeoutSeconds,
+			Value: strconv.FormatInt(*seconds, 10),
+		})
+	}
+}
+
 func WithNamePrefix(prefix string) Option {
 	return func(c *corev1.Container) {
 		c.Name = prefix + c.Name
@@ -21,12 +21,14 @@ const (
 	pipelineResultsVolumeName  = "ocular-pipeline-results"
 	pipelineTargetVolumeName   = "ocular-pipeline-target"
 	pipelineMetadataVolumeName = "ocular-pipeline-metadata"
+	pipelineStorageVolumeName  = "ocular-pipeline-storage"
 
 	runtimeProcVolumeName   = "ocular-runtime-process"
 	runtimeBinaryVolumeName = "ocular-runtime-binaries"
 
 	searchTemplatesVolumeName = "ocular-search-templates"
 	searchFIFOVolumeName      = "ocular-search-fifos"
+	crawlerStateVolumeName    = "ocular-crawler-state"
 
 	/* files */
 
@@ -36,6 +38,9 @@ const (
 	pipelineTemplateName = "pipeline.template.json"
 	pipelineFIFOName     = "pipelines"
 	searchFIFOName       = "searches"
+	schedulerSocketName  = "scheduler.sock"
+	scanSummaryName      = "scan-summary.json"
+	artifactManifestName = "artifact-manifest.json"
 
 	/* directories */
 
@@ -49,6 +54,8 @@ const (
 	pipelineResultsDirectory  = "/mnt/results"
 	pipelineMetadataDirectory = "/mnt/metadata"
 
+	crawlerStateDirectory = "/mnt/state"
+
 	/* path */
 
 	pipelineTemplatePath = configDirectory + "/" + pipelineTemplateName
@@ -58,6 +65,11 @@ const (
 	pipelineFIFOPath = fifoDirectory + "/" + pipelineFIFOName
 	searchFIFOPath   = fifoDirectory + "/" + searchFIFOName
 
+	schedulerSocketPath = fifoDirectory + "/" + schedulerSocketName
+
+	scanSummaryPath      = processDirectory + "/" + scanSummaryName
+	artifactManifestPath = processDirectory + "/" + artifactManifestName
+
 	/* containers */
 
 	uploadContainerPrefix   = "uploader-"
@@ -75,6 +87,16 @@ const (
 
 	searchResourcePrefix = "search-"
 
+	crawlerStateClaimSuffix = "-crawler-state"
+
+	/* security */
+
+	// claimFSGroup is the fsGroup of pods which mount a PersistentVolumeClaim
+	// created by the controller, such as the storage of a MultiPod pipeline or
+	// the crawler state of a CronSearch, so non-root containers can write to the
+	// volume. It matches the nonroot group of the ocular images.
+	claimFSGroup int64 = 65532
+
 	/* finalizers */
 
 	// metricsFinalizer is a finalizer for
@@ -100,6 +122,16 @@ var (
 		},
 	}
 
+	// defaultPipelineStorageSize is the size of the PersistentVolumeClaim
+	// created for pipelines using [v1beta1.PipelineExecutionModeMultiPod]
+	// when [v1beta1.PipelineStorage.Size] is not set.
+	defaultPipelineStorageSize = resource.MustParse("1Gi")
+
+	// defaultCrawlerStateSize is the size of the PersistentVolumeClaim
+	// created for the crawler state of a CronSearch when
+	// [v1beta1.CrawlerStateSpec.Size] is not set.
+	defaultCrawlerStateSize = resource.MustParse("64Mi")
+
 	// schedulerInitResourceRequirements are the resource requirements
 	// for the scheduler init container. This container is the first init
 	// container of the search pod, and just copies the binary to
@@ -116,8 +148,9 @@ var (
 	}
 
 	// podStateChangedPredicate filters pod watch events to only
-	// update when phase changed. Since Create/Delete are not
-	// specified, they will be triggered for every create/delete
+	// update when phase changed or a container terminated, so the
+	// status of each container is reported as it finishes. Since
+	// Create/Delete are not specified, they will be triggered for every create/delete
 	podStateChangedPredicate = predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			oldPod, ok1 := e.ObjectOld.(*corev1.Pod)
@@ -126,10 +159,8 @@ var (
 				return true
 			}
 
-			return oldPod.Status.Phase != newPod.Status.Phase
-			// we may need to check for when container status update
-			// !equality.Semantic.DeepEqual(oldPod.Status.InitContainerStatuses, newPod.Status.InitContainerStatuses) ||
-			// !equality.Semantic.DeepEqual(oldPod.Status.ContainerStatuses, newPod.Status.ContainerStatuses)
+			return oldPod.Status.Phase != newPod.Status.Phase ||
+				terminatedContainers(oldPod) != terminatedContainers(newPod)
 		},
 	}
 )
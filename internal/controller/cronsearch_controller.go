@@ -15,11 +15,13 @@ import (
 	"time"
 
 	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ref "k8s.io/client-go/tools/reference"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1beta1 "github.com/crashappsec/ocular/api/v1beta1"
@@ -50,6 +52,7 @@ type CronSearchReconciler struct {
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=cronsearches/finalizers,verbs=update
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=searches,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=searches/status,verbs=get
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=watch;create;get;list;update;patch;delete
 
 var (
 	scheduledTimeAnnotation = "ocular.crashoverride.run/scheduled-at"
@@ -140,6 +143,11 @@ func (r *CronSearchReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	if err := r.reconcileCrawlerState(ctx, &cronSearch); err != nil {
+		log.Error(err, "unable to reconcile crawler state claim")
+		return ctrl.Result{}, err
+	}
+
 	if cronSearch.Spec.Suspend != nil && *cronSearch.Spec.Suspend {
 		log.V(1).Info("cronsearch suspended, skipping")
 		return ctrl.Result{}, nil
@@ -293,6 +301,9 @@ func constructSearchForCronSearch(cronSearch *v1beta1.CronSearch, scheduledTime
 	maps.Copy(search.Annotations, cronSearch.Spec.SearchTemplate.Annotations)
 	search.Annotations[scheduledTimeAnnotation] = scheduledTime.Format(time.RFC3339)
 	maps.Copy(search.Labels, cronSearch.Spec.SearchTemplate.Labels)
+	if cronSearch.Spec.CrawlerState != nil {
+		search.Spec.CrawlerStateClaimName = crawlerStateClaimName(cronSearch)
+	}
 	if err := ctrl.SetControllerReference(cronSearch, search, scheme); err != nil {
 		return nil, err
 	}
@@ -300,6 +311,74 @@ func constructSearchForCronSearch(cronSearch *v1beta1.CronSearch, scheduledTime
 	return search, nil
 }
 
+// crawlerStateClaimName is the name of the PersistentVolumeClaim
+// holding the crawler state of the CronSearch.
+func crawlerStateClaimName(cronSearch *v1beta1.CronSearch) string {
+	return cronSearch.Name + crawlerStateClaimSuffix
+}
+
+// reconcileCrawlerState creates the PersistentVolumeClaim for the crawler state of the CronSearch
+// if [v1beta1.CronSearchSpec.CrawlerState] is set, otherwise deletes the claim if it was created.
+// Once the CronSearch is deleted, the claim is garbage collected via its owner reference.
+func (r *CronSearchReconciler) reconcileCrawlerState(ctx context.Context, cronSearch *v1beta1.CronSearch) error {
+	log := logf.FromContext(ctx)
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: crawlerStateClaimName(cronSearch), Namespace: cronSearch.Namespace}}
+
+	if cronSearch.Spec.CrawlerState == nil {
+		err := r.Get(ctx, client.ObjectKeyFromObject(claim), claim)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(claim, cronSearch) || !claim.DeletionTimestamp.IsZero() {
+			return nil
+		}
+		log.Info("crawler state was removed from CronSearch, deleting claim", "claim", claim.Name)
+		return client.IgnoreNotFound(r.Delete(ctx, claim))
+	}
+
+	claimOp, err := controllerutil.CreateOrUpdate(ctx, r.Client, claim, func() error {
+		return r.populateCrawlerStateClaim(claim, cronSearch)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to generate crawler state claim: %w", err)
+	}
+	if claimOp == controllerutil.OperationResultCreated {
+		log.Info("crawler state claim was created", "claim", claim.Name)
+	}
+	return nil
+}
+
+// populateCrawlerStateClaim populates the PersistentVolumeClaim for
+// the crawler state of a CronSearch from [v1beta1.CronSearchSpec.CrawlerState].
+func (r *CronSearchReconciler) populateCrawlerStateClaim(claim *corev1.PersistentVolumeClaim, cronSearch *v1beta1.CronSearch) error {
+	// only edit the claim spec if not created yet
+	// since once created, most of the spec is immutable
+	if claim.CreationTimestamp.IsZero() {
+		state := cronSearch.Spec.CrawlerState
+
+		size := defaultCrawlerStateSize
+		if state.Size != nil {
+			size = *state.Size
+		}
+		accessModes := state.AccessModes
+		if len(accessModes) == 0 {
+			accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+		}
+
+		claim.Spec = corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: state.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		}
+	}
+
+	return ctrl.SetControllerReference(cronSearch, claim, r.Scheme)
+}
+
 type categorizedSearches struct {
 	active     []*v1beta1.Search
 	failed     []*v1beta1.Search
@@ -374,6 +453,7 @@ func (r *CronSearchReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.CronSearch{}).
 		Owns(&v1beta1.Search{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
 		Named("cronsearch").
 		Complete(r)
 }
@@ -13,8 +13,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -80,4 +82,133 @@ var _ = Describe("CronSearch Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Context("When the CronSearch has crawler state", func() {
+		const resourceName = "test-crawler-state"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: testNamespace,
+		}
+		claimNamespacedName := types.NamespacedName{
+			Name:      resourceName + crawlerStateClaimSuffix,
+			Namespace: testNamespace,
+		}
+
+		var cronsearch *ocularcrashoverriderunv1beta1.CronSearch
+
+		BeforeEach(func() {
+			By("creating a suspended CronSearch with crawler state")
+			cronsearch = &ocularcrashoverriderunv1beta1.CronSearch{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: testNamespace,
+				},
+				Spec: ocularcrashoverriderunv1beta1.CronSearchSpec{
+					Schedule: "*/1 * * * *",
+					Suspend:  new(true),
+					CrawlerState: &ocularcrashoverriderunv1beta1.CrawlerStateSpec{
+						Size: new(defaultCrawlerStateSize),
+					},
+					SearchTemplate: ocularcrashoverriderunv1beta1.SearchTemplateSpec{
+						Spec: ocularcrashoverriderunv1beta1.SearchSpec{
+							CrawlerRef: ocularcrashoverriderunv1beta1.ParameterizedLocalObjectReference{
+								Name: "example-crawler",
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, cronsearch)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("Cleanup the CronSearch and its claim")
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, cronsearch))).To(Succeed())
+			claim := &corev1.PersistentVolumeClaim{}
+			if err := k8sClient.Get(ctx, claimNamespacedName, claim); err == nil {
+				// no controller removes the pvc-protection finalizer in the test environment
+				claim.Finalizers = nil
+				Expect(k8sClient.Update(ctx, claim)).To(Succeed())
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, claim))).To(Succeed())
+			}
+		})
+
+		It("should create the claim and delete it once crawler state is removed", func() {
+			controllerReconciler := &CronSearchReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Clock:  realClock{},
+			}
+			req := reconcile.Request{NamespacedName: typeNamespacedName}
+
+			By("Creating the claim owned by the CronSearch")
+			_, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			claim := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, claimNamespacedName, claim)).To(Succeed())
+			Expect(claim.Spec.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, defaultCrawlerStateSize))
+			Expect(claim.Spec.AccessModes).To(Equal([]corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, cronsearch)).To(Succeed())
+			Expect(metav1.IsControlledBy(claim, cronsearch)).To(BeTrue())
+
+			By("Keeping the claim on subsequent reconciles")
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, claimNamespacedName, claim)).To(Succeed())
+			Expect(claim.DeletionTimestamp.IsZero()).To(BeTrue())
+
+			By("Deleting the claim once crawler state is removed")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, cronsearch)).To(Succeed())
+			cronsearch.Spec.CrawlerState = nil
+			Expect(k8sClient.Update(ctx, cronsearch)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			// the claim may be held by the pvc-protection finalizer,
+			// so it is either gone or marked for deletion
+			err = k8sClient.Get(ctx, claimNamespacedName, claim)
+			if err == nil {
+				Expect(claim.DeletionTimestamp.IsZero()).To(BeFalse())
+			} else {
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+
+		It("should not delete a claim it does not control", func() {
+			controllerReconciler := &CronSearchReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Clock:  realClock{},
+			}
+
+			By("Creating an unowned claim with the crawler state claim name")
+			claim := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      claimNamespacedName.Name,
+					Namespace: claimNamespacedName.Namespace,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: defaultCrawlerStateSize,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, claim)).To(Succeed())
+
+			cronsearch.Spec.CrawlerState = nil
+			Expect(k8sClient.Update(ctx, cronsearch)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, claimNamespacedName, claim)).To(Succeed())
+			Expect(claim.DeletionTimestamp.IsZero()).To(BeTrue())
+		})
+	})
 })
@@ -12,7 +12,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/crashappsec/ocular/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -54,3 +57,35 @@ func patchStatus(ctx context.Context, c client.Client, obj client.Object, patch
 	}
 	return nil
 }
+
+// terminatedContainers returns the number of init
+// and regular containers of the pod that have terminated.
+func terminatedContainers(pod *corev1.Pod) int {
+	count := 0
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.State.Terminated != nil {
+			count++
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// awaitingSearchCount returns true if the pipeline or search was scheduled by a search that has not
+// completed and has not yet counted the outcome of the child with [v1beta1.CountedBySearchAnnotation].
+// Such children are kept past their TTL, so the success policy of the search sees their outcome.
+func awaitingSearchCount(ctx context.Context, c client.Client, child client.Object) (bool, error) {
+	name := child.GetLabels()[v1beta1.ScheduledByLabelKey]
+	if name == "" || child.GetAnnotations()[v1beta1.CountedBySearchAnnotation] != "" {
+		return false, nil
+	}
+	search := &v1beta1.Search{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: child.GetNamespace()}, search); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return search.Status.CompletionTime == nil, nil
+}
@@ -9,25 +9,32 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"maps"
 	"path"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/crashappsec/ocular/internal/containers"
 	"github.com/crashappsec/ocular/internal/resources"
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
 )
@@ -62,6 +69,27 @@ var (
 		},
 		[]string{"profile", "downloader", "namespace", "phase"},
 	)
+	pipelineRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pipeline_retries_total",
+			Help: "Number of times ocular has recreated a failed scan pod, by failed stage.",
+		},
+		[]string{"profile", "downloader", "namespace", "stage"},
+	)
+	pipelineFindings = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pipeline_findings",
+			Help: "Number of findings in the SARIF and CycloneDX artifacts of the last completed pipeline for a target that still exists, by severity.",
+		},
+		[]string{"profile", "namespace", "target", "severity"},
+	)
+	pipelineSBOMComponents = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pipeline_sbom_components",
+			Help: "Number of components in the CycloneDX artifacts of the last completed pipeline for a target that still exists.",
+		},
+		[]string{"profile", "namespace", "target"},
+	)
 )
 
 func init() {
@@ -71,6 +99,9 @@ func init() {
 		pipelinesRunning,
 		pipelinePodsCreated,
 		pipelineDurationSeconds,
+		pipelineRetries,
+		pipelineFindings,
+		pipelineSBOMComponents,
 	)
 }
 
@@ -80,6 +111,9 @@ type PipelineReconciler struct {
 	Scheme            *runtime.Scheme
 	SidecarImage      string
 	SidecarPullPolicy corev1.PullPolicy
+
+	admissions      pipelineAdmissions
+	findingsMetrics pipelineFindingsMetrics
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -88,15 +122,34 @@ func (r *PipelineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&v1beta1.Pipeline{}).
 		Named("pipeline").
 		Owns(&corev1.Pod{}, builder.WithPredicates(podStateChangedPredicate)).
+		Watches(&v1beta1.Pipeline{},
+			handler.EnqueueRequestsFromMapFunc(r.queuedPipelines),
+			builder.WithPredicates(pipelineFinishedPredicate)).
+		Watches(&v1beta1.PipelineQueue{}, handler.EnqueueRequestsFromMapFunc(r.queuedPipelines)).
+		Watches(&v1beta1.ClusterPipelineQueue{}, handler.EnqueueRequestsFromMapFunc(r.queuedPipelines)).
 		Complete(r)
 }
 
+// queuedPipelines maps any event to all pipelines that are
+// waiting in a queue, so they can re-check for admission.
+func (r *PipelineReconciler) queuedPipelines(ctx context.Context, _ client.Object) []reconcile.Request {
+	requests, err := queuedPipelineRequests(ctx, r.Client)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "unable to list queued pipelines")
+		return nil
+	}
+	return requests
+}
+
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=pipelines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=profiles;downloaders;uploaders,verbs=get;list;watch
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=clusterprofiles;clusterdownloaders;clusteruploaders,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=pipelinequeues;clusterpipelinequeues,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=searches,verbs=get;list;watch
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=pipelines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=pipelines/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=services;pods,verbs=watch;create;get;list;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=watch;create;get;list;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -119,8 +172,12 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	// Fetch the Pipeline instance to be reconciled
 	pipeline := &v1beta1.Pipeline{}
 	err := r.Get(ctx, req.NamespacedName, pipeline)
-	if err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+	if apierrors.IsNotFound(err) {
+		// the findings metrics of a pipeline are only kept while it exists
+		r.findingsMetrics.delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
 	}
 	l = l.WithValues("pipeline", pipeline.Name, "namespace", pipeline.Namespace)
 	ctx = logf.IntoContext(ctx, l)
@@ -146,6 +203,31 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// Ensure the target hash label is set so the scheduler can find
+	// pipelines that have already processed the same target
+	if targetHash := resources.TargetHash(pipeline.Spec); pipeline.Labels[v1beta1.TargetHashLabelKey] != targetHash {
+		patch := client.MergeFrom(pipeline.DeepCopy())
+		if pipeline.Labels == nil {
+			pipeline.Labels = make(map[string]string)
+		}
+		pipeline.Labels[v1beta1.TargetHashLabelKey] = targetHash
+		if err := patchResource(ctx, r.Client, pipeline, patch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set target hash label: %w", err)
+		}
+	}
+
+	if _, requested := pipeline.Annotations[v1beta1.ReuploadAnnotation]; requested {
+		if !pipeline.Status.CompletionTime.IsZero() {
+			return r.handleReupload(ctx, pipeline)
+		}
+		if meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineReuploadingConditionType) {
+			// the reupload has started, but the annotation was not removed
+			if err := r.removeReuploadAnnotation(ctx, pipeline); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
 	// If the pipeline has a completion time, handle post-completion logic
 	if !pipeline.Status.CompletionTime.IsZero() {
 		return r.handlePostCompletion(ctx, pipeline)
@@ -168,7 +250,24 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
-	scanPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pipelineResourcePrefix + pipeline.GetName(), Namespace: pipeline.GetNamespace()}}
+	// Pipelines that have not started must be admitted by
+	// any queues they match before creating the scan pod
+	if pipeline.Status.StartTime.IsZero() {
+		admission, err := checkPipelineAdmission(ctx, r.Client, &r.admissions, pipeline)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to check pipeline queue admission: %w", err)
+		}
+		if admission.Queue != nil {
+			return r.handleQueued(ctx, pipeline, admission)
+		}
+		r.admissions.admit(pipeline, time.Now())
+	}
+
+	if pipeline.Spec.ExecutionMode == v1beta1.PipelineExecutionModeMultiPod {
+		return r.reconcileMultiPod(ctx, pipeline, profile, downloader, uploaders)
+	}
+
+	scanPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: scanPodName(pipeline), Namespace: pipeline.GetNamespace()}}
 	scanPodOp, err := controllerutil.CreateOrUpdate(ctx, r.Client, scanPod, func() error {
 		return r.populateScanPod(scanPod, pipeline, profile, downloader, uploaders)
 	})
@@ -188,48 +287,101 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	if !pipeline.Status.StartTime.IsZero() {
 		if !controllerutil.ContainsFinalizer(pipeline, metricsFinalizer) {
-			patch := client.MergeFrom(pipeline.DeepCopy())
-			controllerutil.AddFinalizer(pipeline, metricsFinalizer)
-			if err := patchResource(ctx, r.Client, pipeline, patch); err != nil {
-				return ctrl.Result{}, fmt.Errorf("failed to add metrics finalizer: %w", err)
-			}
-			l.Info("pipeline starting, incrementing pipeline running count")
-			pipelinesRunning.With(metricLabelsForPipeline(pipeline)).Inc()
-			return ctrl.Result{Priority: new(25)}, nil
+			return r.addMetricsFinalizer(ctx, pipeline)
 		}
 
 		// Check for completion of pods and update status accordingly
-		return r.handleCompletion(logf.IntoContext(ctx, l), pipeline, scanPod)
+		result, err := r.handleCompletion(logf.IntoContext(ctx, l), pipeline, scanPod, profile.Spec.FailurePolicy)
+		if remaining, downloading := downloaderDeadlineRemaining(scanPod, downloader.Spec.ActiveDeadlineSeconds); downloading && result.RequeueAfter == 0 {
+			// requeue to stop the scan pod once the downloader exceeds its deadline
+			result.RequeueAfter = max(remaining, time.Second)
+		}
+		return result, err
 	}
 
 	// Update status to reflect pods have been created
+	err = r.markStarted(logf.IntoContext(ctx, l), pipeline, scanPod.CreationTimestamp, metav1.Condition{
+		Type:    v1beta1.PipelineScanPodCreatedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ScanPodSuccessfullyCreated",
+		Message: fmt.Sprintf("The scan pod %s has been created.", scanPod.Name),
+	})
+	return ctrl.Result{}, err
+
+}
+
+// addMetricsFinalizer adds the metrics finalizer to a pipeline that has started,
+// and increments the running pipelines metric. The finalizer is removed once the
+// pipeline completes or is deleted, decrementing the metric.
+func (r *PipelineReconciler) addMetricsFinalizer(ctx context.Context, pipeline *v1beta1.Pipeline) (ctrl.Result, error) {
+	l := logf.FromContext(ctx)
+	patch := client.MergeFrom(pipeline.DeepCopy())
+	controllerutil.AddFinalizer(pipeline, metricsFinalizer)
+	if err := patchResource(ctx, r.Client, pipeline, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to add metrics finalizer: %w", err)
+	}
+	l.Info("pipeline starting, incrementing pipeline running count")
+	pipelinesRunning.With(metricLabelsForPipeline(pipeline)).Inc()
+	return ctrl.Result{Priority: new(25)}, nil
+}
+
+// markStarted updates the status of the pipeline once the first pod of the pipeline
+// has been created, setting the start time, attempt count and stage statuses.
+// The condition is added to the pipeline with startTime as its transition time.
+func (r *PipelineReconciler) markStarted(ctx context.Context, pipeline *v1beta1.Pipeline, startTime metav1.Time, condition metav1.Condition) error {
+	l := logf.FromContext(ctx)
 	l.Info("marking pipeline as started")
-	startTime := scanPod.CreationTimestamp
 	patch := client.MergeFrom(pipeline.DeepCopy())
-	reason, message := "ScanPodSuccessfullyCreated", fmt.Sprintf("The scan pod %s has been created.", scanPod.Name)
-	pipeline.Status.Conditions = append(pipeline.Status.Conditions, metav1.Condition{
-		Type:               v1beta1.PipelineScanPodCreatedConditionType,
-		Status:             metav1.ConditionTrue,
-		Reason:             reason,
-		Message:            message,
-		LastTransitionTime: startTime.Rfc3339Copy(),
-	})
+	condition.LastTransitionTime = startTime.Rfc3339Copy()
+	pipeline.Status.Conditions = append(pipeline.Status.Conditions, condition)
+	if meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineQueuedConditionType) {
+		meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+			Type:    v1beta1.PipelineQueuedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Admitted",
+			Message: "The pipeline has been admitted by all queues.",
+		})
+	}
 
 	pipeline.Status.StartTime = &startTime
+	pipeline.Status.Attempts = 1
 	pipeline.Status.Phase = v1beta1.PipelineDownloading
 	pipeline.Status.StageStatuses.DownloadStatus = v1beta1.PipelineStageInProgress
 	pipeline.Status.StageStatuses.ScanStatus = v1beta1.PipelineStageNotStarted
-	err = patchStatus(logf.IntoContext(ctx, l), r.Client, pipeline, patch)
-	return ctrl.Result{}, err
+	return patchStatus(ctx, r.Client, pipeline, patch)
+}
+
+// handleQueued marks the pipeline as waiting in the queue from admission
+// and requeues the pipeline to check for admission again.
+func (r *PipelineReconciler) handleQueued(ctx context.Context, pipeline *v1beta1.Pipeline, admission pipelineQueueAdmission) (ctrl.Result, error) {
+	l := logf.FromContext(ctx)
+	l.Info("pipeline is waiting for queue", "queue", admission.Queue.String(), "position", admission.Position)
 
+	patch := client.MergeFrom(pipeline.DeepCopy())
+	phaseChanged := pipeline.Status.Phase != v1beta1.PipelinePending
+	pipeline.Status.Phase = v1beta1.PipelinePending
+	conditionChanged := meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+		Type:    v1beta1.PipelineQueuedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "QueueLimitReached",
+		Message: fmt.Sprintf("The pipeline is waiting for a slot in %s.", admission.Queue),
+	})
+	if phaseChanged || conditionChanged {
+		if err := patchStatus(ctx, r.Client, pipeline, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: pipelineQueuedRequeueInterval, Priority: new(int(pipelinePriority(pipeline)))}, nil
 }
 
-func (r *PipelineReconciler) handleCompletion(ctx context.Context, pipeline *v1beta1.Pipeline, scanPod *corev1.Pod) (ctrl.Result, error) {
+func (r *PipelineReconciler) handleCompletion(ctx context.Context, pipeline *v1beta1.Pipeline, scanPod *corev1.Pod, failurePolicy v1beta1.ScannerFailurePolicy) (ctrl.Result, error) {
 	l := logf.FromContext(ctx)
 	l.Info("checking for scan & upload pod completion")
 
 	t := metav1.NewTime(time.Now())
 	patch := client.MergeFrom(pipeline.DeepCopy())
+	pipeline.Status.ContainerStatuses = mergePipelineContainerStatuses(pipeline.Status.ContainerStatuses, scanPod)
+	setPipelineArtifactStatuses(ctx, pipeline, scanPod)
 
 	switch scanPod.Status.Phase {
 	case corev1.PodSucceeded:
@@ -256,8 +408,26 @@ func (r *PipelineReconciler) handleCompletion(ctx context.Context, pipeline *v1b
 		if pipeline.Status.StageStatuses.UploadStatus != v1beta1.PipelineStageSkipped {
 			pipeline.Status.StageStatuses.UploadStatus = uploadStatus
 		}
+		if !scannerFailuresAllowUpload(pipeline, failurePolicy) && uploadStatus == v1beta1.PipelineStageCompleted {
+			// the sidecar exits without running the uploaders
+			pipeline.Status.StageStatuses.UploadStatus = v1beta1.PipelineStageSkipped
+		}
+		if phase := completedPipelinePhase(pipeline, failurePolicy); phase != v1beta1.PipelineFailed {
+			// scanners failed, but the failure policy allowed the results to be uploaded
+			pipeline.Status.Phase = phase
+			pipeline.Status.CompletionTime = new(t)
+			pipeline.Status.Conditions = append(pipeline.Status.Conditions, toleratedScannerFailuresCondition(pipeline, t))
+			break
+		}
+		if stage := failedPipelineStage(pipeline.Status.StageStatuses); shouldRetryPipeline(pipeline, stage) {
+			return r.handleRetry(ctx, pipeline, scanPod, stage, patch)
+		}
 		pipeline.Status.Phase = v1beta1.PipelineFailed
 		pipeline.Status.CompletionTime = new(t)
+		if stage := timedOutPipelineStage(scanPod); stage != "" {
+			pipeline.Status.Conditions = append(pipeline.Status.Conditions, stageTimedOutCondition(stage, t))
+			break
+		}
 		pipeline.Status.Conditions = append(pipeline.Status.Conditions,
 			metav1.Condition{
 				Type:               v1beta1.CompletedSuccessfullyConditionType,
@@ -289,7 +459,7 @@ func (r *PipelineReconciler) handleCompletion(ctx context.Context, pipeline *v1b
 		if pipeline.Status.StageStatuses.UploadStatus != v1beta1.PipelineStageSkipped {
 			pipeline.Status.StageStatuses.UploadStatus = v1beta1.PipelineStageNotStarted
 		}
-		pipeline.Status.Phase = v1beta1.PipelineSucceeded
+		pipeline.Status.Phase = v1beta1.PipelinePending
 	default:
 		// scan pod in unknown state, requeue for further investigation
 		l.Error(fmt.Errorf("scan pod in unknown state"), "scan pod is in an unknown state", "phase", scanPod.Status.Phase, "name", pipeline.GetName())
@@ -302,6 +472,53 @@ func (r *PipelineReconciler) handleCompletion(ctx context.Context, pipeline *v1b
 	return ctrl.Result{}, err
 }
 
+// handleRetry is called when the scan pod (or stage pod for [v1beta1.PipelineExecutionModeMultiPod])
+// has failed and the pipeline's retry policy allows another attempt. The pipeline waits in the
+// [v1beta1.PipelinePending] phase until the backoff has passed, then increments the attempt
+// counter so the next reconciliation creates a new pod.
+func (r *PipelineReconciler) handleRetry(ctx context.Context, pipeline *v1beta1.Pipeline, scanPod *corev1.Pod, stage v1beta1.PipelineStage, patch client.Patch) (ctrl.Result, error) {
+	l := logf.FromContext(ctx).WithValues("attempt", pipeline.Status.Attempts, "failed-stage", stage)
+
+	backoff := pipelineRetryBackoff(pipeline)
+	pipeline.Status.Phase = v1beta1.PipelinePending
+	if wait := time.Until(scanPodFinishedAt(scanPod).Add(backoff)); wait > 0 {
+		l.Info("scan pod failed, waiting before retrying", "backoff", backoff.String(), "requeue-after", wait.String())
+		meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+			Type:    v1beta1.PipelineRetryingConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ScanPodFailed",
+			Message: fmt.Sprintf("The pod %s failed in the %s stage, it will be recreated after %s.", scanPod.Name, stage, backoff),
+		})
+		if err := patchStatus(logf.IntoContext(ctx, l), r.Client, pipeline, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	l.Info("retrying pipeline after scan pod failure")
+	pipeline.Status.Attempts = max(pipeline.Status.Attempts, 1) + 1
+	if pipeline.Spec.ExecutionMode == v1beta1.PipelineExecutionModeMultiPod {
+		// only the failed stage and the stages after it need to run again
+		resetPipelineStages(&pipeline.Status.StageStatuses, stage)
+	} else {
+		resetPipelineStages(&pipeline.Status.StageStatuses, v1beta1.PipelineStageDownload)
+	}
+	meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+		Type:    v1beta1.PipelineRetryingConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ScanPodRecreated",
+		Message: fmt.Sprintf("The pod %s failed in the %s stage, starting attempt %d.", scanPod.Name, stage, pipeline.Status.Attempts),
+	})
+	if err := patchStatus(logf.IntoContext(ctx, l), r.Client, pipeline, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	metricLabels := metricLabelsForPipeline(pipeline)
+	metricLabels["stage"] = string(stage)
+	pipelineRetries.With(metricLabels).Inc()
+	return ctrl.Result{Priority: new(25)}, nil
+}
+
 func (r *PipelineReconciler) populateScanPod(
 	pod *corev1.Pod,
 	pipeline *v1beta1.Pipeline,
@@ -441,12 +658,19 @@ func (r *PipelineReconciler) populateScanPod(
 		// aritfactArgs are the arguments passed to
 		// uploaders to specify which artifacts to extract
 		artifactArgs := generateArtifactArguments(downloader.Spec.MetadataFiles, profile.Spec.Artifacts)
+		artifactChecks, err := generateArtifactChecks(profile.Spec.ArtifactChecks)
+		if err != nil {
+			return err
+		}
 
 		uploaderContainers := make([]corev1.Container, 0, len(uploaders))
 		uploaderLabels, uploaderAnnotations := make(map[string]string), make(map[string]string)
 		parentParams := resources.ParseParameters(profile.Spec.Parameters, profile.Parameters, nil)
 		for _, invocation := range uploaders {
 			baseContainer := invocation.Spec.Container
+			if invocation.Spec.S3 != nil {
+				baseContainer = r.s3UploaderContainer(invocation.Metadata.Name, invocation.Spec.S3)
+			}
 
 			maps.Copy(uploaderLabels, invocation.Metadata.GetLabels())
 			maps.Copy(uploaderAnnotations, invocation.Metadata.GetAnnotations())
@@ -459,6 +683,7 @@ func (r *PipelineReconciler) populateScanPod(
 						Name:  v1beta1.EnvVarUploaderName,
 						Value: invocation.Metadata.Name,
 					}),
+					containers.WithTimeout(invocation.Spec.ActiveDeadlineSeconds),
 				),
 			)
 			pod.Spec.Volumes = append(pod.Spec.Volumes, invocation.Spec.Volumes...)
@@ -472,6 +697,18 @@ func (r *PipelineReconciler) populateScanPod(
 			containers.WithAdditionalEnvVars(corev1.EnvVar{
 				Name:  v1beta1.EnvVarScanContainerNames,
 				Value: strings.Join(scanContainerNames, ","),
+			}, corev1.EnvVar{
+				Name:  v1beta1.EnvVarScannerFailurePolicy,
+				Value: string(profile.Spec.FailurePolicy),
+			}, corev1.EnvVar{
+				Name:  v1beta1.EnvVarScanSummaryPath,
+				Value: scanSummaryPath,
+			}, corev1.EnvVar{
+				Name:  v1beta1.EnvVarArtifactManifestPath,
+				Value: artifactManifestPath,
+			}, corev1.EnvVar{
+				Name:  v1beta1.EnvVarArtifactChecks,
+				Value: artifactChecks,
 			}),
 			containers.WithNamePrefix(uploadContainerPrefix),
 		)
@@ -484,6 +721,7 @@ func (r *PipelineReconciler) populateScanPod(
 
 		pod.Spec.ServiceAccountName = pipeline.Spec.ServiceAccountName
 		pod.Spec.RuntimeClassName = pipeline.Spec.RuntimeClassName
+		pod.Spec.PriorityClassName = pipeline.Spec.PriorityClassName
 		pod.Spec.RestartPolicy = corev1.RestartPolicyNever
 		pod.Spec.InitContainers = containers.ApplyStandardOptions(initContainers)
 		pod.Spec.Containers = containers.ApplyStandardOptions(
@@ -511,6 +749,8 @@ func (r *PipelineReconciler) populateScanPod(
 		}
 		maps.Copy(pod.Annotations, resources.PropagateMetadata(downloader.Metadata.GetAnnotations(), profile.Metadata.GetAnnotations(), uploaderAnnotations))
 
+	} else {
+		enforceDownloaderDeadline(pod, downloader.Spec.ActiveDeadlineSeconds)
 	}
 
 	return ctrl.SetControllerReference(pipeline, pod, r.Scheme)
@@ -519,24 +759,39 @@ func (r *PipelineReconciler) populateScanPod(
 func generateArtifactArguments(metadataFiles []string, artifacts []string) []string {
 	args := []string{"--"}
 	for _, artifact := range artifacts {
-		artifactPath := path.Clean(artifact)
-		if path.IsAbs(artifactPath) {
-			args = append(args, artifactPath)
-		} else {
-			args = append(args, path.Join(v1beta1.PipelineResultsDirectory, artifactPath))
-		}
+		args = append(args, resolveArtifactPath(v1beta1.PipelineResultsDirectory, artifact))
 	}
 	for _, artifact := range metadataFiles {
-		artifactPath := path.Clean(artifact)
-		if path.IsAbs(artifactPath) {
-			args = append(args, artifactPath)
-		} else {
-			args = append(args, path.Join(v1beta1.PipelineMetadataDirectory, artifactPath))
-		}
+		args = append(args, resolveArtifactPath(v1beta1.PipelineMetadataDirectory, artifact))
 	}
 	return args
 }
 
+// resolveArtifactPath returns the absolute path of an artifact,
+// resolving relative paths against dir.
+func resolveArtifactPath(dir, artifact string) string {
+	artifactPath := path.Clean(artifact)
+	if path.IsAbs(artifactPath) {
+		return artifactPath
+	}
+	return path.Join(dir, artifactPath)
+}
+
+// generateArtifactChecks returns the value of [v1beta1.EnvVarArtifactChecks]
+// for the checks of a profile, with each path resolved like the artifact arguments.
+func generateArtifactChecks(checks []v1beta1.ArtifactCheck) (string, error) {
+	resolved := make([]v1beta1.ArtifactCheck, 0, len(checks))
+	for _, check := range checks {
+		check.Path = resolveArtifactPath(v1beta1.PipelineResultsDirectory, check.Path)
+		resolved = append(resolved, check)
+	}
+	value, err := json.Marshal(resolved)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode artifact checks: %w", err)
+	}
+	return string(value), nil
+}
+
 func generateBasePipelineEnvironment(pipeline *v1beta1.Pipeline) []corev1.EnvVar {
 	return []corev1.EnvVar{
 		{
@@ -611,6 +866,9 @@ func (r *PipelineReconciler) handlePostCompletion(ctx context.Context, pipeline
 		duration := pipeline.Status.CompletionTime.Sub(pipeline.Status.StartTime.Time)
 		pipelinesCompleted.With(metricLabels).Add(1)
 		pipelineDurationSeconds.With(metricLabels).Observe(duration.Seconds())
+		if pipeline.Status.Findings != nil {
+			r.findingsMetrics.set(pipeline)
+		}
 		l.Info("pipeline metrics updated with completion")
 	}
 	if pipeline.Spec.TTLSecondsAfterFinished == nil {
@@ -621,6 +879,16 @@ func (r *PipelineReconciler) handlePostCompletion(ctx context.Context, pipeline
 	ttl := time.Duration(*pipeline.Spec.TTLSecondsAfterFinished) * time.Second
 	wait := time.Until(pipeline.Status.CompletionTime.Add(ttl))
 	if wait <= 0 {
+		awaiting, err := awaitingSearchCount(ctx, r.Client, pipeline)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if awaiting {
+			// the pipeline is reconciled again once the search marks it as counted
+			l.Info("pipeline has exceeded its TTL, waiting for the search that scheduled it to count it",
+				"ttl", ttl, "search", pipeline.Labels[v1beta1.ScheduledByLabelKey])
+			return ctrl.Result{}, nil
+		}
 		l.Info("pipeline has exceeded its TTL, deleting",
 			"ttl", ttl)
 		return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, pipeline))
@@ -637,7 +905,7 @@ func (r *PipelineReconciler) handlePostCompletion(ctx context.Context, pipeline
 func determineScanPodStageStatuses(scanPod *corev1.Pod) (download, scan, upload v1beta1.PipelineStageStatus) {
 	for _, cs := range scanPod.Status.InitContainerStatuses {
 		if cs.Name != sidecarInitContainerName {
-			if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+			if cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0 {
 				download = v1beta1.PipelineStageCompleted
 			} else if cs.State.Terminated != nil {
 				download = v1beta1.PipelineStageFailed
@@ -675,6 +943,8 @@ func determineScanPodStageStatuses(scanPod *corev1.Pod) (download, scan, upload
 	case v1beta1.PipelineStageSkipped:
 		upload = v1beta1.PipelineStageSkipped
 	default:
+		// skipped unless the pod has at least one uploader
+		upload = v1beta1.PipelineStageSkipped
 		for _, cs := range scanPod.Status.ContainerStatuses {
 			if strings.HasPrefix(cs.Name, uploadContainerPrefix) {
 				if cs.State.Terminated == nil {
@@ -684,6 +954,7 @@ func determineScanPodStageStatuses(scanPod *corev1.Pod) (download, scan, upload
 					upload = v1beta1.PipelineStageFailed
 					break
 				}
+				upload = v1beta1.PipelineStageCompleted
 			}
 
 		}
@@ -691,6 +962,251 @@ func determineScanPodStageStatuses(scanPod *corev1.Pod) (download, scan, upload
 	return download, scan, upload
 }
 
+// failedScanners returns the names of the scan containers that exited with a
+// non-zero exit code, and the total number of scan containers of the pipeline.
+func failedScanners(statuses []v1beta1.PipelineContainerStatus) (failed []string, total int) {
+	for _, s := range statuses {
+		if s.Stage != v1beta1.PipelineStageScan {
+			continue
+		}
+		total++
+		if s.ExitCode != nil && *s.ExitCode != 0 {
+			failed = append(failed, s.Name)
+		}
+	}
+	return failed, total
+}
+
+// scannerFailuresAllowUpload returns true if the scan stage of the pipeline did not fail,
+// or only failed because of scanners that the [v1beta1.ScannerFailurePolicy] of the
+// profile allows the uploaders to run after.
+func scannerFailuresAllowUpload(pipeline *v1beta1.Pipeline, policy v1beta1.ScannerFailurePolicy) bool {
+	if pipeline.Status.StageStatuses.ScanStatus != v1beta1.PipelineStageFailed {
+		return true
+	}
+	failed, total := failedScanners(pipeline.Status.ContainerStatuses)
+	// a failed scan stage without a failed scanner means the pod
+	// was stopped by the system, which never allows uploading
+	return len(failed) > 0 && resources.ScannerFailuresAllowUpload(policy, len(failed), total)
+}
+
+// scannerFailuresTolerated returns true if the scan stage of the pipeline did not fail, or
+// the [v1beta1.ScannerFailurePolicy] of the profile allows the pipeline to complete without
+// failing after the scanners that failed. [v1beta1.ScannerFailurePolicyUploadThenFail] allows
+// the uploaders to run, but never tolerates the failures.
+func scannerFailuresTolerated(pipeline *v1beta1.Pipeline, policy v1beta1.ScannerFailurePolicy) bool {
+	switch policy {
+	case v1beta1.ScannerFailurePolicyUploadOnPartialFailure, v1beta1.ScannerFailurePolicyIgnoreScannerFailures:
+		return scannerFailuresAllowUpload(pipeline, policy)
+	default:
+		return pipeline.Status.StageStatuses.ScanStatus != v1beta1.PipelineStageFailed
+	}
+}
+
+// completedPipelinePhase returns the phase of a pipeline once all of its stages have finished,
+// applying the [v1beta1.ScannerFailurePolicy] of the profile to any failed scanners.
+func completedPipelinePhase(pipeline *v1beta1.Pipeline, policy v1beta1.ScannerFailurePolicy) v1beta1.PipelinePhase {
+	statuses := pipeline.Status.StageStatuses
+	switch {
+	case statuses.DownloadStatus != v1beta1.PipelineStageCompleted,
+		statuses.UploadStatus == v1beta1.PipelineStageFailed,
+		!scannerFailuresTolerated(pipeline, policy):
+		return v1beta1.PipelineFailed
+	case statuses.ScanStatus != v1beta1.PipelineStageFailed,
+		policy == v1beta1.ScannerFailurePolicyIgnoreScannerFailures:
+		return v1beta1.PipelineSucceeded
+	default:
+		return v1beta1.PipelinePartiallySucceeded
+	}
+}
+
+// toleratedScannerFailuresCondition returns the completion condition for a pipeline
+// where scanners failed, but the failure policy allowed the results to be uploaded.
+func toleratedScannerFailuresCondition(pipeline *v1beta1.Pipeline, t metav1.Time) metav1.Condition {
+	failed, _ := failedScanners(pipeline.Status.ContainerStatuses)
+	status, reason := metav1.ConditionFalse, "ScannersFailed"
+	if pipeline.Status.Phase == v1beta1.PipelineSucceeded {
+		status, reason = metav1.ConditionTrue, "ScannerFailuresIgnored"
+	}
+	return metav1.Condition{
+		Type:               v1beta1.PipelineCompletedSuccessfullyConditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            fmt.Sprintf("The pipeline has completed, but the scanners %s failed.", strings.Join(failed, ", ")),
+		LastTransitionTime: t,
+	}
+}
+
+// mergePipelineContainerStatuses updates the pipeline container statuses with the statuses
+// of the downloader, scanner and uploader containers from the pod. Statuses of containers
+// not in the pod are kept, since pipelines using [v1beta1.PipelineExecutionModeMultiPod]
+// have a pod for each stage.
+func mergePipelineContainerStatuses(statuses []v1beta1.PipelineContainerStatus, pod *corev1.Pod) []v1beta1.PipelineContainerStatus {
+	merged := slices.Clone(statuses)
+	for _, cs := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		stage := containerPipelineStage(cs.Name)
+		if stage == "" {
+			continue
+		}
+
+		status := v1beta1.PipelineContainerStatus{
+			Name:    cs.Name,
+			Stage:   stage,
+			Image:   cs.Image,
+			PodName: pod.Name,
+		}
+		switch {
+		case cs.State.Terminated != nil:
+			status.ExitCode = new(cs.State.Terminated.ExitCode)
+			status.Reason = cs.State.Terminated.Reason
+			status.StartedAt = new(cs.State.Terminated.StartedAt)
+			status.FinishedAt = new(cs.State.Terminated.FinishedAt)
+		case cs.State.Running != nil:
+			status.StartedAt = new(cs.State.Running.StartedAt)
+		case cs.State.Waiting != nil:
+			status.Reason = cs.State.Waiting.Reason
+		}
+
+		if i := slices.IndexFunc(merged, func(s v1beta1.PipelineContainerStatus) bool { return s.Name == cs.Name }); i >= 0 {
+			merged[i] = status
+		} else {
+			merged = append(merged, status)
+		}
+	}
+
+	stageOrder := []v1beta1.PipelineStage{v1beta1.PipelineStageDownload, v1beta1.PipelineStageScan, v1beta1.PipelineStageUpload}
+	slices.SortStableFunc(merged, func(a, b v1beta1.PipelineContainerStatus) int {
+		return slices.Index(stageOrder, a.Stage) - slices.Index(stageOrder, b.Stage)
+	})
+	return merged
+}
+
+// setPipelineArtifactStatuses updates the artifact statuses of the pipeline from the
+// termination message written by the sidecar of the first uploader of the pod,
+// setting the [v1beta1.PipelineArtifactsValidConditionType] condition.
+func setPipelineArtifactStatuses(ctx context.Context, pipeline *v1beta1.Pipeline, pod *corev1.Pod) {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if !strings.HasPrefix(cs.Name, uploadContainerPrefix) || cs.State.Terminated == nil || cs.State.Terminated.Message == "" {
+			continue
+		}
+		var message resources.UploaderTerminationMessage
+		if err := json.Unmarshal([]byte(cs.State.Terminated.Message), &message); err != nil {
+			// the uploader overwrote the termination message, or it was truncated
+			logf.FromContext(ctx).Error(err, "unable to parse termination message of uploader, artifact statuses were not updated",
+				"container", cs.Name, "message-size", len(cs.State.Terminated.Message))
+			continue
+		}
+
+		pipeline.Status.Artifacts = message.Artifacts
+		pipeline.Status.Findings = totalPipelineFindings(message.Artifacts)
+		var failed []string
+		for _, a := range message.Artifacts {
+			if a.Error != "" {
+				failed = append(failed, a.Path)
+			}
+		}
+		condition := metav1.Condition{
+			Type:    v1beta1.PipelineArtifactsValidConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ArtifactChecksPassed",
+			Message: "All artifacts exist and passed their checks.",
+		}
+		if len(failed) > 0 {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "ArtifactChecksFailed"
+			condition.Message = fmt.Sprintf("The artifacts %s are missing or failed their checks, and were not uploaded.", strings.Join(failed, ", "))
+		}
+		meta.SetStatusCondition(&pipeline.Status.Conditions, condition)
+		return
+	}
+}
+
+// totalPipelineFindings returns the total of the findings of each artifact,
+// or nil if no artifact had its findings summarized.
+func totalPipelineFindings(artifacts []v1beta1.PipelineArtifactStatus) *v1beta1.PipelineFindings {
+	var total *v1beta1.PipelineFindings
+	for _, a := range artifacts {
+		if a.Findings == nil {
+			continue
+		}
+		if total == nil {
+			total = &v1beta1.PipelineFindings{}
+		}
+		total.Total += a.Findings.Total
+		total.Components += a.Findings.Components
+		for severity, count := range a.Findings.BySeverity {
+			if total.BySeverity == nil {
+				total.BySeverity = map[string]int32{}
+			}
+			total.BySeverity[severity] += count
+		}
+	}
+	return total
+}
+
+// pipelineFindingsMetrics records which pipeline last set the findings metrics for each
+// profile and target, so the metrics can be deleted once that pipeline is deleted.
+type pipelineFindingsMetrics struct {
+	mu sync.Mutex
+	// labels maps each pipeline to the labels of the findings metrics it last set.
+	labels map[types.NamespacedName]prometheus.Labels
+}
+
+// set sets the findings metrics for the profile and target of the pipeline,
+// replacing the findings of any previous pipeline for the same profile and target.
+func (m *pipelineFindingsMetrics) set(pipeline *v1beta1.Pipeline) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := prometheus.Labels{
+		"namespace": pipeline.Namespace,
+		"profile":   pipeline.Spec.ProfileRef.Name,
+		"target":    pipeline.Spec.Target.Identifier,
+	}
+	if m.labels == nil {
+		m.labels = make(map[types.NamespacedName]prometheus.Labels)
+	}
+	maps.DeleteFunc(m.labels, func(_ types.NamespacedName, previous prometheus.Labels) bool {
+		return maps.Equal(previous, labels)
+	})
+	m.labels[client.ObjectKeyFromObject(pipeline)] = maps.Clone(labels)
+
+	pipelineFindings.DeletePartialMatch(labels)
+	pipelineSBOMComponents.With(labels).Set(float64(pipeline.Status.Findings.Components))
+	for severity, count := range pipeline.Status.Findings.BySeverity {
+		labels["severity"] = severity
+		pipelineFindings.With(labels).Set(float64(count))
+	}
+}
+
+// delete deletes the findings metrics set by the pipeline, unless a
+// later pipeline for the same profile and target has replaced them.
+func (m *pipelineFindingsMetrics) delete(pipeline types.NamespacedName) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels, ok := m.labels[pipeline]
+	if !ok {
+		return
+	}
+	delete(m.labels, pipeline)
+	pipelineFindings.DeletePartialMatch(labels)
+	pipelineSBOMComponents.Delete(labels)
+}
+
+// containerPipelineStage returns the stage a container of a pipeline pod belongs to,
+// or an empty stage for containers added by ocular (e.g. the sidecar).
+func containerPipelineStage(name string) v1beta1.PipelineStage {
+	switch {
+	case strings.HasPrefix(name, downloadContainerPrefix):
+		return v1beta1.PipelineStageDownload
+	case strings.HasPrefix(name, scanContainerPrefix):
+		return v1beta1.PipelineStageScan
+	case strings.HasPrefix(name, uploadContainerPrefix):
+		return v1beta1.PipelineStageUpload
+	default:
+		return ""
+	}
+}
+
 func uploaderInvocationsFromProfile(ctx context.Context, c client.Client, namespace string, uploaderRefs []v1beta1.ParameterizedLocalObjectReference) ([]resources.Invocation[v1beta1.UploaderSpec], error) {
 	var uploaders []resources.Invocation[v1beta1.UploaderSpec]
 	for _, uploaderRef := range uploaderRefs {
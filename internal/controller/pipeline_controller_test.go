@@ -11,16 +11,24 @@ package controller
 import (
 	"math/rand"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/resources"
 	testutils "github.com/crashappsec/ocular/test/utils"
 )
 
@@ -180,6 +188,7 @@ var _ = Describe("Pipeline Controller", func() {
 				Name:      pipeline.Name,
 				Namespace: pipeline.Namespace,
 			}, pipeline)).To(Succeed())
+			Expect(pipeline.Labels).To(HaveKeyWithValue(v1beta1.TargetHashLabelKey, resources.TargetHash(pipeline.Spec)))
 
 			scanPod := &corev1.Pod{}
 			err = k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: pipeline.Namespace}, scanPod)
@@ -611,6 +620,922 @@ var _ = Describe("Pipeline Controller", func() {
 		})
 
 	})
+
+	When("a pipeline queue has reached its limit", func() {
+		var (
+			suffix   = testutils.GenerateRandomString(rnd, 5, testutils.LowercaseAlphabeticLetterSet)
+			profile  *v1beta1.Profile
+			queue    *v1beta1.PipelineQueue
+			active   *v1beta1.Pipeline
+			pipeline *v1beta1.Pipeline
+		)
+
+		BeforeEach(func() {
+			profile = &v1beta1.Profile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.ProfileSpec{
+					Containers: []v1beta1.ConditionalContainer{
+						{
+							Container: corev1.Container{
+								Image: testImage,
+								Name:  "scanner",
+							},
+						},
+					},
+				},
+			}
+			queue = &v1beta1.PipelineQueue{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-queue-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.PipelineQueueSpec{
+					MaxActivePipelines: 1,
+					Profiles:           []string{profile.Name},
+				},
+			}
+			newPipeline := func(name string) *v1beta1.Pipeline {
+				return &v1beta1.Pipeline{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+					},
+					Spec: v1beta1.PipelineSpec{
+						DownloaderRef: v1beta1.ParameterizedLocalObjectReference{
+							Name: downloader.Name,
+						},
+						ProfileRef: v1beta1.ParameterizedLocalObjectReference{
+							Name: profile.Name,
+							Kind: "Profile",
+						},
+						Target: v1beta1.Target{
+							Identifier: "https://example.com/samplefile.txt",
+						},
+					},
+				}
+			}
+			active = newPipeline("test-active-pipeline-" + suffix)
+			pipeline = newPipeline("test-queued-pipeline-" + suffix)
+
+			Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+			Expect(k8sClient.Create(ctx, queue)).To(Succeed())
+			Expect(k8sClient.Create(ctx, active)).To(Succeed())
+			active.Status.StartTime = new(metav1.Now())
+			Expect(k8sClient.Status().Update(ctx, active)).To(Succeed())
+			Expect(k8sClient.Create(ctx, pipeline)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = k8sClient.Delete(ctx, pipeline)
+			_ = k8sClient.Delete(ctx, active)
+			Expect(k8sClient.Delete(ctx, queue)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+		})
+
+		It("should queue the pipeline until a slot is available", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      pipeline.Name,
+					Namespace: pipeline.Namespace,
+				},
+			}
+
+			By("Keeping the pipeline pending while the queue is full")
+			result, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelinePending))
+			Expect(meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineQueuedConditionType)).To(BeTrue())
+
+			scanPod := &corev1.Pod{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: pipeline.Namespace}, scanPod)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			By("Admitting the pipeline once the active pipeline completes")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(active), active)).To(Succeed())
+			active.Status.CompletionTime = new(metav1.Now())
+			Expect(k8sClient.Status().Update(ctx, active)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: pipeline.Namespace}, scanPod)).To(Succeed())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(pipeline.Status.Conditions, v1beta1.PipelineQueuedConditionType)).To(BeTrue())
+		})
+
+		It("should admit higher priority pipelines first", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			urgent := &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-urgent-pipeline-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: *pipeline.Spec.DeepCopy(),
+			}
+			priorityClass := &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-priority-" + suffix},
+				Value:      1000,
+			}
+			Expect(k8sClient.Create(ctx, priorityClass)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, priorityClass) }()
+
+			urgent.Spec.Priority = new(int32(10))
+			urgent.Spec.PriorityClassName = priorityClass.Name
+			Expect(k8sClient.Create(ctx, urgent)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, urgent) }()
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(active), active)).To(Succeed())
+			active.Status.CompletionTime = new(metav1.Now())
+			Expect(k8sClient.Status().Update(ctx, active)).To(Succeed())
+
+			By("Keeping the older, lower priority pipeline queued")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pipeline), pipeline)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineQueuedConditionType)).To(BeTrue())
+
+			By("Admitting the higher priority pipeline")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(urgent)})
+			Expect(err).NotTo(HaveOccurred())
+			scanPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + urgent.Name, Namespace: urgent.Namespace}, scanPod)).To(Succeed())
+			Expect(scanPod.Spec.PriorityClassName).To(Equal(priorityClass.Name))
+		})
+
+		It("should count admitted pipelines that have not started in the cache as active", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			admitted := &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-admitted-pipeline-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: *pipeline.Spec.DeepCopy(),
+			}
+			admitted.Spec.Priority = new(int32(-10))
+			Expect(k8sClient.Create(ctx, admitted)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, admitted) }()
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(active), active)).To(Succeed())
+			active.Status.CompletionTime = new(metav1.Now())
+			Expect(k8sClient.Status().Update(ctx, active)).To(Succeed())
+
+			By("Keeping the pipeline queued behind a lower priority pipeline that was already admitted")
+			controllerReconciler.admissions.admit(admitted, time.Now())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pipeline), pipeline)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineQueuedConditionType)).To(BeTrue())
+
+			By("Forgetting the admission once it expires")
+			Expect(controllerReconciler.admissions.isAdmitted(admitted, time.Now().Add(pipelineAdmissionTTL))).To(BeFalse())
+		})
+	})
+
+	When("a pipeline has a retry policy", func() {
+		var (
+			suffix   = testutils.GenerateRandomString(rnd, 5, testutils.LowercaseAlphabeticLetterSet)
+			profile  *v1beta1.Profile
+			pipeline *v1beta1.Pipeline
+		)
+
+		BeforeEach(func() {
+			profile = &v1beta1.Profile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.ProfileSpec{
+					Containers: []v1beta1.ConditionalContainer{
+						{
+							Container: corev1.Container{
+								Image: testImage,
+								Name:  "scanner",
+							},
+						},
+					},
+				},
+			}
+			pipeline = &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-retry-pipeline-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.PipelineSpec{
+					DownloaderRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: downloader.Name,
+					},
+					ProfileRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: profile.Name,
+						Kind: "Profile",
+					},
+					Target: v1beta1.Target{
+						Identifier: "https://example.com/samplefile.txt",
+					},
+					RetryPolicy: &v1beta1.PipelineRetryPolicy{
+						MaxRetries:     1,
+						BackoffSeconds: new(int32(0)),
+						Stages:         []v1beta1.PipelineStage{v1beta1.PipelineStageDownload},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+			Expect(k8sClient.Create(ctx, pipeline)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = k8sClient.Delete(ctx, pipeline)
+			Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+		})
+
+		It("should recreate the scan pod until the retries are exhausted", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)}
+			failDownload := func(podName string) {
+				scanPod := &corev1.Pod{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: podName, Namespace: namespace}, scanPod)).To(Succeed())
+				finishedAt := metav1.NewTime(metav1.Now().Add(-time.Second))
+				scanPod.Status.Phase = corev1.PodFailed
+				scanPod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+					{
+						Name:  sidecarInitContainerName,
+						Image: sidecarImage,
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, FinishedAt: finishedAt}},
+					},
+					{
+						Name:  downloadContainerPrefix + downloadContainerName,
+						Image: testImage,
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, FinishedAt: finishedAt}},
+					},
+				}
+				Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+			}
+
+			By("Creating the first scan pod")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Attempts).To(Equal(int32(1)))
+
+			By("Recreating the scan pod after the download fails")
+			failDownload(pipelineResourcePrefix + pipeline.Name)
+			_, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Attempts).To(Equal(int32(2)))
+			Expect(pipeline.Status.CompletionTime).To(BeNil())
+			Expect(pipeline.Status.ContainerStatuses).To(ConsistOf(SatisfyAll(
+				HaveField("Name", downloadContainerPrefix+downloadContainerName),
+				HaveField("Stage", v1beta1.PipelineStageDownload),
+				HaveField("PodName", pipelineResourcePrefix+pipeline.Name),
+				HaveField("ExitCode", HaveValue(Equal(int32(1)))),
+			)))
+			Expect(meta.IsStatusConditionFalse(pipeline.Status.Conditions, v1beta1.PipelineRetryingConditionType)).To(BeTrue())
+
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			retryPodName := scanPodName(pipeline)
+			Expect(retryPodName).To(Equal(pipelineResourcePrefix + pipeline.Name + "-2"))
+
+			By("Failing the pipeline once the retries are exhausted")
+			failDownload(retryPodName)
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelineFailed))
+			Expect(pipeline.Status.StageStatuses.DownloadStatus).To(Equal(v1beta1.PipelineStageFailed))
+			Expect(pipeline.Status.Attempts).To(Equal(int32(2)))
+		})
+
+		It("should wait for the backoff before recreating the scan pod", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)}
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			pipeline.Spec.RetryPolicy.BackoffSeconds = new(int32(60))
+			Expect(k8sClient.Update(ctx, pipeline)).To(Succeed())
+
+			By("Creating the first scan pod")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("Waiting for the backoff after the download fails")
+			scanPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: namespace}, scanPod)).To(Succeed())
+			finishedAt := metav1.NewTime(metav1.Now().Add(-time.Second))
+			scanPod.Status.Phase = corev1.PodFailed
+			scanPod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name:  downloadContainerPrefix + downloadContainerName,
+					Image: testImage,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, FinishedAt: finishedAt}},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+
+			for range 2 {
+				result, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(And(BeNumerically(">", 50*time.Second), BeNumerically("<=", 60*time.Second)))
+				Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+				Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelinePending))
+				Expect(pipeline.Status.Attempts).To(Equal(int32(1)))
+				Expect(pipeline.Status.CompletionTime).To(BeNil())
+				Expect(meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineRetryingConditionType)).To(BeTrue())
+			}
+		})
+	})
+
+	When("a pipeline uses the multi pod execution mode", func() {
+		var (
+			suffix   = testutils.GenerateRandomString(rnd, 5, testutils.LowercaseAlphabeticLetterSet)
+			profile  *v1beta1.Profile
+			pipeline *v1beta1.Pipeline
+		)
+
+		BeforeEach(func() {
+			profile = &v1beta1.Profile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.ProfileSpec{
+					Containers: []v1beta1.ConditionalContainer{
+						{
+							Container: corev1.Container{
+								Image: testImage,
+								Name:  "scanner",
+							},
+						},
+					},
+				},
+			}
+			pipeline = &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-multipod-pipeline-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.PipelineSpec{
+					DownloaderRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: downloader.Name,
+					},
+					ProfileRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: profile.Name,
+						Kind: "Profile",
+					},
+					Target: v1beta1.Target{
+						Identifier: "https://example.com/samplefile.txt",
+					},
+					ExecutionMode: v1beta1.PipelineExecutionModeMultiPod,
+				},
+			}
+			Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+			Expect(k8sClient.Create(ctx, pipeline)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = k8sClient.Delete(ctx, pipeline)
+			Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+		})
+
+		It("should run each stage in its own pod", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)}
+
+			By("Creating the storage claim and download pod")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			claim := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: namespace}, claim)).To(Succeed())
+			Expect(claim.Spec.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, defaultPipelineStorageSize))
+
+			downloadPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name + "-download", Namespace: namespace}, downloadPod)).To(Succeed())
+			Expect(downloadPod.Spec.InitContainers).To(BeEmpty())
+			Expect(downloadPod.Spec.Containers).To(HaveLen(1))
+			Expect(downloadPod.Spec.Containers[0].Name).To(Equal(downloadContainerPrefix + downloadContainerName))
+			Expect(downloadPod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      pipelineStorageVolumeName,
+				MountPath: pipelineTargetDirectory,
+				SubPath:   "target",
+			}))
+			Expect(downloadPod.Labels).To(HaveKeyWithValue(v1beta1.PipelineStageLabelKey, string(v1beta1.PipelineStageDownload)))
+			Expect(downloadPod.Spec.SecurityContext).NotTo(BeNil())
+			Expect(downloadPod.Spec.SecurityContext.FSGroup).To(Equal(new(claimFSGroup)))
+
+			By("Creating the scan pod once the download completes")
+			downloadPod.Status.Phase = corev1.PodSucceeded
+			Expect(k8sClient.Status().Update(ctx, downloadPod)).To(Succeed())
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.StageStatuses.DownloadStatus).To(Equal(v1beta1.PipelineStageCompleted))
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelineScanning))
+
+			scanPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name + "-scan", Namespace: namespace}, scanPod)).To(Succeed())
+			Expect(scanPod.Spec.InitContainers).To(HaveLen(1))
+			Expect(scanPod.Spec.InitContainers[0].Name).To(Equal(sidecarInitContainerName))
+			Expect(scanPod.Spec.Containers).To(HaveLen(1))
+			Expect(scanPod.Spec.Containers[0].Name).To(Equal(scanContainerPrefix + "scanner"))
+
+			By("Completing the pipeline without an upload pod")
+			scanPod.Status.Phase = corev1.PodSucceeded
+			Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelineSucceeded))
+			Expect(pipeline.Status.StageStatuses.UploadStatus).To(Equal(v1beta1.PipelineStageSkipped))
+
+			// record the completion of the pipeline in the metrics
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Finalizers).NotTo(ContainElement(metricsFinalizer))
+
+			By("Re-running the upload stage when requested")
+			pipeline.Annotations = map[string]string{v1beta1.ReuploadAnnotation: "true"}
+			Expect(k8sClient.Update(ctx, pipeline)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Annotations).NotTo(HaveKey(v1beta1.ReuploadAnnotation))
+			Expect(pipeline.Status.CompletionTime).To(BeNil())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelineUploading))
+			Expect(pipeline.Status.Attempts).To(Equal(int32(1)), "a re-upload should not use up the retries of the pipeline")
+			Expect(pipeline.Status.Reuploads).To(Equal(int32(1)))
+			Expect(stagePodName(pipeline, v1beta1.PipelineStageUpload)).To(Equal(pipelineResourcePrefix + pipeline.Name + "-upload-reupload-1"))
+			Expect(meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineReuploadingConditionType)).To(BeTrue())
+
+			// the profile has no uploaders, so the pipeline completes without an upload pod
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelineSucceeded))
+			Expect(meta.IsStatusConditionFalse(pipeline.Status.Conditions, v1beta1.PipelineReuploadingConditionType)).To(BeTrue())
+			Expect(pipeline.Finalizers).NotTo(ContainElement(metricsFinalizer))
+		})
+
+		It("should retry only the failed stage after the backoff", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)}
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			pipeline.Spec.RetryPolicy = &v1beta1.PipelineRetryPolicy{
+				MaxRetries:     1,
+				BackoffSeconds: new(int32(60)),
+			}
+			Expect(k8sClient.Update(ctx, pipeline)).To(Succeed())
+			failScan := func(finishedAt time.Time) {
+				scanPod := &corev1.Pod{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name + "-scan", Namespace: namespace}, scanPod)).To(Succeed())
+				scanPod.Status.Phase = corev1.PodFailed
+				scanPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+					{
+						Name:  scanContainerPrefix + "scanner",
+						Image: testImage,
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, FinishedAt: metav1.NewTime(finishedAt)}},
+					},
+				}
+				Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+			}
+
+			By("Running the download and scan stages")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			downloadPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name + "-download", Namespace: namespace}, downloadPod)).To(Succeed())
+			downloadPod.Status.Phase = corev1.PodSucceeded
+			Expect(k8sClient.Status().Update(ctx, downloadPod)).To(Succeed())
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("Keeping the scan stage in progress while waiting for the backoff")
+			failScan(time.Now().Add(-time.Second))
+			for range 2 {
+				result, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 50*time.Second))
+				Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+				Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelinePending))
+				Expect(pipeline.Status.Attempts).To(Equal(int32(1)))
+				Expect(pipeline.Status.StageStatuses.ScanStatus).To(Equal(v1beta1.PipelineStageInProgress))
+				Expect(pipeline.Status.CompletionTime).To(BeNil())
+				Expect(meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineRetryingConditionType)).To(BeTrue())
+			}
+
+			By("Recreating the scan pod once the backoff has passed")
+			failScan(time.Now().Add(-2 * time.Minute))
+			_, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Attempts).To(Equal(int32(2)))
+			Expect(pipeline.Status.StageStatuses.DownloadStatus).To(Equal(v1beta1.PipelineStageCompleted))
+			Expect(pipeline.Status.StageStatuses.ScanStatus).To(Equal(v1beta1.PipelineStageNotStarted))
+
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			retryPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name + "-2-scan", Namespace: namespace}, retryPod)).To(Succeed())
+		})
+	})
+
+	When("a pipeline uses a profile that allows uploading after scanner failures", func() {
+		var (
+			suffix   = testutils.GenerateRandomString(rnd, 5, testutils.LowercaseAlphabeticLetterSet)
+			uploader *v1beta1.Uploader
+			profile  *v1beta1.Profile
+			pipeline *v1beta1.Pipeline
+		)
+
+		BeforeEach(func() {
+			uploader = &v1beta1.Uploader{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-uploader-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.UploaderSpec{
+					Container: corev1.Container{
+						Name:  "uploader",
+						Image: testImage,
+					},
+				},
+			}
+			profile = &v1beta1.Profile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.ProfileSpec{
+					Containers: []v1beta1.ConditionalContainer{
+						{
+							Container: corev1.Container{
+								Image: testImage,
+								Name:  "failing",
+							},
+						},
+						{
+							Container: corev1.Container{
+								Image: testImage,
+								Name:  "passing",
+							},
+						},
+					},
+					UploaderRefs: []v1beta1.ParameterizedLocalObjectReference{
+						{Name: uploader.Name},
+					},
+					FailurePolicy: v1beta1.ScannerFailurePolicyUploadOnPartialFailure,
+				},
+			}
+			pipeline = &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-partial-pipeline-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.PipelineSpec{
+					DownloaderRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: downloader.Name,
+					},
+					ProfileRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: profile.Name,
+						Kind: "Profile",
+					},
+					Target: v1beta1.Target{
+						Identifier: "https://example.com/samplefile.txt",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, uploader)).To(Succeed())
+			Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+			Expect(k8sClient.Create(ctx, pipeline)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = k8sClient.Delete(ctx, pipeline)
+			Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, uploader)).To(Succeed())
+		})
+
+		It("should partially succeed when only some scanners fail", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)}
+
+			By("Creating the scan pod")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			scanPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: namespace}, scanPod)).To(Succeed())
+			for _, c := range scanPod.Spec.Containers {
+				if strings.HasPrefix(c.Name, uploadContainerPrefix) {
+					Expect(c.Env).To(ContainElements(corev1.EnvVar{
+						Name:  v1beta1.EnvVarScannerFailurePolicy,
+						Value: string(v1beta1.ScannerFailurePolicyUploadOnPartialFailure),
+					}, corev1.EnvVar{
+						Name:  v1beta1.EnvVarScanSummaryPath,
+						Value: scanSummaryPath,
+					}, corev1.EnvVar{
+						Name:  v1beta1.EnvVarArtifactManifestPath,
+						Value: artifactManifestPath,
+					}))
+				}
+			}
+
+			By("Failing one of the scanners")
+			finishedAt := metav1.NewTime(metav1.Now().Add(-time.Second))
+			terminated := func(exitCode int32) corev1.ContainerState {
+				return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, FinishedAt: finishedAt}}
+			}
+			scanPod.Status.Phase = corev1.PodFailed
+			scanPod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+				{Name: sidecarInitContainerName, Image: sidecarImage, State: terminated(0)},
+				{Name: downloadContainerPrefix + downloadContainerName, Image: testImage, State: terminated(0)},
+			}
+			scanPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: scanContainerPrefix + "failing", Image: testImage, State: terminated(1)},
+				{Name: scanContainerPrefix + "passing", Image: testImage, State: terminated(0)},
+				{Name: uploadContainerPrefix + "uploader", Image: testImage, State: terminated(0)},
+			}
+			// written by the sidecar after checking the artifacts
+			scanPod.Status.ContainerStatuses[2].State.Terminated.Message = `{"artifacts":[` +
+				`{"path":"/mnt/results/report.json","present":false,"error":"artifact does not exist"},` +
+				`{"path":"/mnt/results/results.sarif","present":true,"sizeBytes":512,"findings":{"total":3,"bySeverity":{"high":2,"low":1}}}]}`
+			Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelinePartiallySucceeded))
+			Expect(pipeline.Status.CompletionTime).NotTo(BeNil())
+			Expect(pipeline.Status.StageStatuses.ScanStatus).To(Equal(v1beta1.PipelineStageFailed))
+			Expect(pipeline.Status.StageStatuses.UploadStatus).To(Equal(v1beta1.PipelineStageCompleted))
+			Expect(pipeline.Status.Conditions).To(ContainElement(SatisfyAll(
+				HaveField("Type", v1beta1.PipelineCompletedSuccessfullyConditionType),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", "ScannersFailed"),
+			)))
+			Expect(pipeline.Status.Artifacts).To(ConsistOf(v1beta1.PipelineArtifactStatus{
+				Path:  "/mnt/results/report.json",
+				Error: "artifact does not exist",
+			}, v1beta1.PipelineArtifactStatus{
+				Path:      "/mnt/results/results.sarif",
+				Present:   true,
+				SizeBytes: 512,
+				Findings:  &v1beta1.ArtifactFindings{Total: 3, BySeverity: map[string]int32{"high": 2, "low": 1}},
+			}))
+			Expect(pipeline.Status.Findings).To(Equal(&v1beta1.PipelineFindings{Total: 3, BySeverity: map[string]int32{"high": 2, "low": 1}}))
+			Expect(meta.IsStatusConditionFalse(pipeline.Status.Conditions, v1beta1.PipelineArtifactsValidConditionType)).To(BeTrue())
+
+			By("Deleting the findings metrics once the pipeline is deleted")
+			labels := prometheus.Labels{
+				"namespace": pipeline.Namespace,
+				"profile":   pipeline.Spec.ProfileRef.Name,
+				"target":    pipeline.Spec.Target.Identifier,
+			}
+			controllerReconciler.findingsMetrics.set(pipeline)
+			Expect(testutil.ToFloat64(pipelineFindings.With(prometheus.Labels{
+				"namespace": labels["namespace"],
+				"profile":   labels["profile"],
+				"target":    labels["target"],
+				"severity":  "high",
+			}))).To(Equal(2.0))
+			pipeline.Finalizers = nil
+			Expect(k8sClient.Update(ctx, pipeline)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, pipeline)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pipelineFindings.DeletePartialMatch(labels)).To(BeZero())
+			Expect(pipelineSBOMComponents.Delete(labels)).To(BeFalse())
+		})
+
+		It("should upload the results and fail the pipeline with the default policy", func() {
+			profile.Spec.FailurePolicy = v1beta1.ScannerFailurePolicyUploadThenFail
+			Expect(k8sClient.Update(ctx, profile)).To(Succeed())
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)}
+
+			By("Creating the scan pod")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			scanPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: namespace}, scanPod)).To(Succeed())
+
+			By("Failing one of the scanners")
+			terminated := func(exitCode int32) corev1.ContainerState {
+				return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, FinishedAt: metav1.Now()}}
+			}
+			scanPod.Status.Phase = corev1.PodFailed
+			scanPod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+				{Name: sidecarInitContainerName, Image: sidecarImage, State: terminated(0)},
+				{Name: downloadContainerPrefix + downloadContainerName, Image: testImage, State: terminated(0)},
+			}
+			scanPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: scanContainerPrefix + "failing", Image: testImage, State: terminated(1)},
+				{Name: scanContainerPrefix + "passing", Image: testImage, State: terminated(0)},
+				{Name: uploadContainerPrefix + "uploader", Image: testImage, State: terminated(0)},
+			}
+			Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelineFailed))
+			Expect(pipeline.Status.StageStatuses.ScanStatus).To(Equal(v1beta1.PipelineStageFailed))
+			Expect(pipeline.Status.StageStatuses.UploadStatus).To(Equal(v1beta1.PipelineStageCompleted))
+		})
+	})
+
+	When("a pipeline has stage deadlines", func() {
+		var (
+			suffix   = testutils.GenerateRandomString(rnd, 5, testutils.LowercaseAlphabeticLetterSet)
+			profile  *v1beta1.Profile
+			pipeline *v1beta1.Pipeline
+		)
+
+		BeforeEach(func() {
+			downloader.Spec.ActiveDeadlineSeconds = new(int64(60))
+			Expect(k8sClient.Update(ctx, downloader)).To(Succeed())
+			profile = &v1beta1.Profile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.ProfileSpec{
+					Containers: []v1beta1.ConditionalContainer{
+						{
+							Container: corev1.Container{
+								Image: testImage,
+								Name:  "scanner",
+							},
+							ActiveDeadlineSeconds: new(int64(30)),
+						},
+					},
+				},
+			}
+			pipeline = &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-deadline-pipeline-" + suffix,
+					Namespace: namespace,
+				},
+				Spec: v1beta1.PipelineSpec{
+					DownloaderRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: downloader.Name,
+					},
+					ProfileRef: v1beta1.ParameterizedLocalObjectReference{
+						Name: profile.Name,
+						Kind: "Profile",
+					},
+					Target: v1beta1.Target{
+						Identifier: "https://example.com/samplefile.txt",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+			Expect(k8sClient.Create(ctx, pipeline)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = k8sClient.Delete(ctx, pipeline)
+			Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+		})
+
+		It("should fail the pipeline once the downloader exceeds its deadline", func() {
+			controllerReconciler := &PipelineReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SidecarImage:      sidecarImage,
+				SidecarPullPolicy: corev1.PullIfNotPresent,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pipeline)}
+			scanPodKey := types.NamespacedName{Name: pipelineResourcePrefix + pipeline.Name, Namespace: namespace}
+
+			By("Passing the scanner deadline to the sidecar")
+			for range 2 {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			scanPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, scanPodKey, scanPod)).To(Succeed())
+			Expect(scanPod.Spec.ActiveDeadlineSeconds).To(BeNil())
+			Expect(scanPod.Spec.Containers).To(ContainElement(SatisfyAll(
+				HaveField("Name", scanContainerPrefix+"scanner"),
+				HaveField("Env", ContainElement(corev1.EnvVar{Name: v1beta1.EnvVarTimeoutSeconds, Value: "30"})),
+			)))
+
+			By("Requeuing while the downloader is within its deadline")
+			startedAt := metav1.NewTime(metav1.Now().Add(-30 * time.Second).Truncate(time.Second))
+			scanPod.Status.Phase = corev1.PodPending
+			scanPod.Status.StartTime = new(startedAt)
+			scanPod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name:  sidecarInitContainerName,
+					Image: sidecarImage,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, FinishedAt: startedAt}},
+				},
+				{
+					Name:  downloadContainerPrefix + downloadContainerName,
+					Image: testImage,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: startedAt}},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+			result, err := controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Second, 5*time.Second))
+			Expect(k8sClient.Get(ctx, scanPodKey, scanPod)).To(Succeed())
+			Expect(scanPod.Spec.ActiveDeadlineSeconds).To(BeNil())
+
+			By("Setting the pod deadline once the downloader exceeds its deadline")
+			startedAt = metav1.NewTime(metav1.Now().Add(-2 * time.Minute).Truncate(time.Second))
+			scanPod.Status.StartTime = new(startedAt)
+			scanPod.Status.InitContainerStatuses[1].State.Running.StartedAt = startedAt
+			Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, scanPodKey, scanPod)).To(Succeed())
+			Expect(scanPod.Spec.ActiveDeadlineSeconds).To(HaveValue(BeNumerically(">=", 120)))
+
+			By("Failing the pipeline once the pod is stopped")
+			scanPod.Status.Phase = corev1.PodFailed
+			scanPod.Status.Reason = podDeadlineExceededReason
+			scanPod.Status.InitContainerStatuses[1].State = corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, FinishedAt: metav1.Now()},
+			}
+			Expect(k8sClient.Status().Update(ctx, scanPod)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, req.NamespacedName, pipeline)).To(Succeed())
+			Expect(pipeline.Status.Phase).To(Equal(v1beta1.PipelineFailed))
+			Expect(pipeline.Status.StageStatuses.DownloadStatus).To(Equal(v1beta1.PipelineStageFailed))
+			Expect(pipeline.Status.Conditions).To(ContainElement(SatisfyAll(
+				HaveField("Type", v1beta1.CompletedSuccessfullyConditionType),
+				HaveField("Reason", "StageTimedOut"),
+			)))
+		})
+	})
 })
 
 func ValidatePipelinePodSpec(podSpec corev1.PodSpec,
@@ -0,0 +1,491 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// pipelineStorageSubPaths maps the EmptyDir volumes of the scan pod to the
+// sub path of the pipeline's PersistentVolumeClaim that replaces them
+// when running with [v1beta1.PipelineExecutionModeMultiPod].
+var pipelineStorageSubPaths = map[string]string{
+	pipelineTargetVolumeName:   "target",
+	pipelineResultsVolumeName:  "results",
+	pipelineMetadataVolumeName: "metadata",
+	runtimeProcVolumeName:      "proc",
+}
+
+// reconcileMultiPod reconciles a pipeline using [v1beta1.PipelineExecutionModeMultiPod].
+// A PersistentVolumeClaim is created for the pipeline, then a pod is created for
+// each stage once the stage before it has completed. The stage statuses of the pipeline
+// are used to determine which stage is currently running.
+func (r *PipelineReconciler) reconcileMultiPod(
+	ctx context.Context,
+	pipeline *v1beta1.Pipeline,
+	profile resources.Invocation[v1beta1.ProfileSpec],
+	downloader resources.Invocation[v1beta1.DownloaderSpec],
+	uploaders []resources.Invocation[v1beta1.UploaderSpec],
+) (ctrl.Result, error) {
+	l := logf.FromContext(ctx)
+
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pipelineResourcePrefix + pipeline.GetName(), Namespace: pipeline.GetNamespace()}}
+	claimOp, err := controllerutil.CreateOrUpdate(ctx, r.Client, claim, func() error {
+		return r.populateStorageClaim(claim, pipeline)
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to generate pipeline storage claim: %w", err)
+	}
+	if claimOp == controllerutil.OperationResultCreated {
+		l.Info("pipeline storage claim was created", "claim", claim.Name)
+	}
+
+	stage := currentPipelineStage(pipeline.Status.StageStatuses)
+	if stage == "" || (stage == v1beta1.PipelineStageUpload && len(uploaders) == 0) {
+		// all stages have finished, but the pipeline was not marked complete
+		return r.handleStageCompletion(ctx, pipeline, stage, nil, profile.Spec)
+	}
+
+	stagePod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: stagePodName(pipeline, stage), Namespace: pipeline.GetNamespace()}}
+	stagePodOp, err := controllerutil.CreateOrUpdate(ctx, r.Client, stagePod, func() error {
+		return r.populateStagePod(stagePod, stage, claim.Name, pipeline, profile, downloader, uploaders)
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to generate new %s pod: %w", strings.ToLower(string(stage)), err)
+	}
+
+	l = l.WithValues("stage", stage, "stage-pod", stagePod.Name)
+	ctx = logf.IntoContext(ctx, l)
+
+	switch stagePodOp {
+	case controllerutil.OperationResultCreated:
+		pipelinePodsCreated.With(metricLabelsForPipeline(pipeline)).Inc()
+		fallthrough
+	case controllerutil.OperationResultUpdated:
+		l.Info("stage pod was created or modified", "op", stagePodOp)
+	}
+
+	if pipeline.Status.StartTime.IsZero() {
+		err = r.markStarted(ctx, pipeline, stagePod.CreationTimestamp, metav1.Condition{
+			Type:    v1beta1.PipelineDownloadPodCreatedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "DownloadPodSuccessfullyCreated",
+			Message: fmt.Sprintf("The download pod %s has been created.", stagePod.Name),
+		})
+		return ctrl.Result{}, err
+	}
+
+	// a reupload is not counted as another run of the pipeline,
+	// since its completion was already recorded in the metrics
+	reuploading := meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineReuploadingConditionType)
+	if !reuploading && !controllerutil.ContainsFinalizer(pipeline, metricsFinalizer) {
+		return r.addMetricsFinalizer(ctx, pipeline)
+	}
+
+	return r.handleStageCompletion(ctx, pipeline, stage, stagePod, profile.Spec)
+}
+
+// handleStageCompletion updates the status of the pipeline from the pod of the current stage.
+// Once the stage pod completes the status of the next stage is set, causing the pod for the next
+// stage to be created in the following reconciliation. If there is no next stage, the pipeline
+// is marked as completed.
+func (r *PipelineReconciler) handleStageCompletion(
+	ctx context.Context,
+	pipeline *v1beta1.Pipeline,
+	stage v1beta1.PipelineStage,
+	stagePod *corev1.Pod,
+	profile v1beta1.ProfileSpec,
+) (ctrl.Result, error) {
+	l := logf.FromContext(ctx)
+	l.Info("checking for stage pod completion")
+
+	t := metav1.NewTime(time.Now())
+	patch := client.MergeFrom(pipeline.DeepCopy())
+	statuses := &pipeline.Status.StageStatuses
+
+	if stagePod != nil {
+		pipeline.Status.ContainerStatuses = mergePipelineContainerStatuses(pipeline.Status.ContainerStatuses, stagePod)
+		setPipelineArtifactStatuses(ctx, pipeline, stagePod)
+		if stage != v1beta1.PipelineStageDownload {
+			conditionType := v1beta1.PipelineScanPodCreatedConditionType
+			if stage == v1beta1.PipelineStageUpload {
+				conditionType = v1beta1.PipelineUploadPodCreatedConditionType
+			}
+			meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+				Type:    conditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  string(stage) + "PodSuccessfullyCreated",
+				Message: fmt.Sprintf("The %s pod %s has been created.", strings.ToLower(string(stage)), stagePod.Name),
+			})
+		}
+
+		switch stagePod.Status.Phase {
+		case corev1.PodPending, corev1.PodRunning:
+			setPipelineStageStatus(statuses, stage, v1beta1.PipelineStageInProgress)
+			pipeline.Status.Phase = pipelinePhaseForStage(stage)
+		case corev1.PodSucceeded:
+			setPipelineStageStatus(statuses, stage, v1beta1.PipelineStageCompleted)
+		case corev1.PodFailed:
+			setPipelineStageStatus(statuses, stage, v1beta1.PipelineStageFailed)
+			tolerated := scannerFailuresTolerated(pipeline, profile.FailurePolicy)
+			if !tolerated && shouldRetryPipeline(pipeline, stage) {
+				// the stage stays in progress while waiting for the backoff,
+				// otherwise the next reconciliation would move on to the next stage
+				setPipelineStageStatus(statuses, stage, v1beta1.PipelineStageInProgress)
+				return r.handleRetry(ctx, pipeline, stagePod, stage, patch)
+			}
+			if stage == v1beta1.PipelineStageDownload {
+				// nothing to scan or upload without a target
+				statuses.ScanStatus = v1beta1.PipelineStageSkipped
+				statuses.UploadStatus = v1beta1.PipelineStageSkipped
+			} else if !scannerFailuresAllowUpload(pipeline, profile.FailurePolicy) {
+				// the failure policy does not allow the uploaders to run
+				statuses.UploadStatus = v1beta1.PipelineStageSkipped
+			}
+		default:
+			// stage pod in unknown state, requeue for further investigation
+			l.Error(fmt.Errorf("stage pod in unknown state"), "stage pod is in an unknown state", "phase", stagePod.Status.Phase, "name", pipeline.GetName())
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+		}
+	}
+
+	// without uploaders, the pipeline is finished once the scan stage is
+	if len(profile.UploaderRefs) == 0 && currentPipelineStage(*statuses) == v1beta1.PipelineStageUpload {
+		statuses.UploadStatus = v1beta1.PipelineStageSkipped
+	}
+
+	if next := currentPipelineStage(*statuses); next == "" {
+		phase := completedPipelinePhase(pipeline, profile.FailurePolicy)
+		pipeline.Status.CompletionTime = new(t)
+		if meta.IsStatusConditionTrue(pipeline.Status.Conditions, v1beta1.PipelineReuploadingConditionType) {
+			meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+				Type:    v1beta1.PipelineReuploadingConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  "ReuploadCompleted",
+				Message: "The uploaders have finished running against the saved results.",
+			})
+		}
+		pipeline.Status.Phase = phase
+		switch {
+		case phase == v1beta1.PipelineSucceeded && statuses.ScanStatus != v1beta1.PipelineStageFailed:
+			pipeline.Status.Conditions = append(pipeline.Status.Conditions,
+				metav1.Condition{
+					Type:               v1beta1.PipelineCompletedSuccessfullyConditionType,
+					Status:             metav1.ConditionTrue,
+					Reason:             "PodsCompletedSuccessfully",
+					Message:            "The pipeline has completed successfully.",
+					LastTransitionTime: t,
+				})
+		case phase == v1beta1.PipelineFailed && timedOutPipelineStage(stagePod) != "":
+			pipeline.Status.Conditions = append(pipeline.Status.Conditions, stageTimedOutCondition(timedOutPipelineStage(stagePod), t))
+		case phase == v1beta1.PipelineFailed:
+			pipeline.Status.Conditions = append(pipeline.Status.Conditions,
+				metav1.Condition{
+					Type:               v1beta1.CompletedSuccessfullyConditionType,
+					Status:             metav1.ConditionFalse,
+					Reason:             "PodTerminatedWithFailures",
+					Message:            fmt.Sprintf("The pipeline %s pod has failed.", strings.ToLower(string(failedPipelineStage(*statuses)))),
+					LastTransitionTime: t,
+				})
+		default:
+			pipeline.Status.Conditions = append(pipeline.Status.Conditions, toleratedScannerFailuresCondition(pipeline, t))
+		}
+	} else if next != stage {
+		// the pod for the next stage is created in the next reconciliation
+		setPipelineStageStatus(statuses, next, v1beta1.PipelineStageInProgress)
+		pipeline.Status.Phase = pipelinePhaseForStage(next)
+	}
+
+	l = l.WithValues("phase", pipeline.Status.Phase)
+	err := patchStatus(logf.IntoContext(ctx, l), r.Client, pipeline, patch)
+	return ctrl.Result{}, err
+}
+
+// handleReupload handles a completed pipeline with the [v1beta1.ReuploadAnnotation].
+// If the pipeline's results were saved, the pipeline is moved back to the upload stage,
+// then the annotation is removed. The next reconciliation then creates a new upload pod
+// that runs the uploaders against the results in the pipeline's PersistentVolumeClaim.
+// The status is updated before removing the annotation so that the request is not lost
+// if the status fails to update.
+func (r *PipelineReconciler) handleReupload(ctx context.Context, pipeline *v1beta1.Pipeline) (ctrl.Result, error) {
+	l := logf.FromContext(ctx)
+
+	patch := client.MergeFrom(pipeline.DeepCopy())
+	var reason, message string
+	switch {
+	case pipeline.Spec.ExecutionMode != v1beta1.PipelineExecutionModeMultiPod:
+		reason, message = "ExecutionModeNotSupported", "The results of the pipeline were not saved, re-uploading requires the MultiPod execution mode."
+	case !slices.Contains([]v1beta1.PipelineStageStatus{v1beta1.PipelineStageCompleted, v1beta1.PipelineStageFailed}, pipeline.Status.StageStatuses.ScanStatus):
+		reason, message = "ScanStageNotRun", "The scan stage of the pipeline did not run, there are no results to upload."
+	}
+	if reason != "" {
+		l.Info("unable to re-upload pipeline results", "reason", reason)
+		meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+			Type:    v1beta1.PipelineReuploadingConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: message,
+		})
+		if err := patchStatus(ctx, r.Client, pipeline, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.removeReuploadAnnotation(ctx, pipeline)
+	}
+
+	l.Info("re-uploading pipeline results")
+	pipeline.Status.CompletionTime = nil
+	pipeline.Status.Reuploads++
+	pipeline.Status.Phase = v1beta1.PipelineUploading
+	pipeline.Status.StageStatuses.UploadStatus = v1beta1.PipelineStageInProgress
+	meta.RemoveStatusCondition(&pipeline.Status.Conditions, v1beta1.PipelineCompletedSuccessfullyConditionType)
+	meta.RemoveStatusCondition(&pipeline.Status.Conditions, v1beta1.CompletedSuccessfullyConditionType)
+	meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+		Type:    v1beta1.PipelineReuploadingConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReuploadRequested",
+		Message: fmt.Sprintf("The uploaders are running again for re-upload %d.", pipeline.Status.Reuploads),
+	})
+	if err := patchStatus(ctx, r.Client, pipeline, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.removeReuploadAnnotation(ctx, pipeline); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Priority: new(25)}, nil
+}
+
+// removeReuploadAnnotation removes the [v1beta1.ReuploadAnnotation] from the pipeline
+// once the status has been updated for the request.
+func (r *PipelineReconciler) removeReuploadAnnotation(ctx context.Context, pipeline *v1beta1.Pipeline) error {
+	patch := client.MergeFrom(pipeline.DeepCopy())
+	delete(pipeline.Annotations, v1beta1.ReuploadAnnotation)
+	if err := patchResource(ctx, r.Client, pipeline, patch); err != nil {
+		return fmt.Errorf("failed to remove reupload annotation: %w", err)
+	}
+	return nil
+}
+
+// currentPipelineStage returns the first stage of the pipeline that
+// has not finished, or an empty stage if all stages have finished.
+// A failed scan stage is considered finished, since uploaders are
+// still run for failed scanners.
+func currentPipelineStage(statuses v1beta1.PipelineStageStatuses) v1beta1.PipelineStage {
+	unfinished := func(status v1beta1.PipelineStageStatus) bool {
+		return status == "" || status == v1beta1.PipelineStageNotStarted || status == v1beta1.PipelineStageInProgress
+	}
+	switch {
+	case unfinished(statuses.DownloadStatus):
+		return v1beta1.PipelineStageDownload
+	case unfinished(statuses.ScanStatus):
+		return v1beta1.PipelineStageScan
+	case unfinished(statuses.UploadStatus):
+		return v1beta1.PipelineStageUpload
+	}
+	return ""
+}
+
+// setPipelineStageStatus sets the status of a single stage.
+func setPipelineStageStatus(statuses *v1beta1.PipelineStageStatuses, stage v1beta1.PipelineStage, status v1beta1.PipelineStageStatus) {
+	switch stage {
+	case v1beta1.PipelineStageDownload:
+		statuses.DownloadStatus = status
+	case v1beta1.PipelineStageScan:
+		statuses.ScanStatus = status
+	case v1beta1.PipelineStageUpload:
+		statuses.UploadStatus = status
+	}
+}
+
+// pipelinePhaseForStage returns the phase of a pipeline while the stage is running.
+func pipelinePhaseForStage(stage v1beta1.PipelineStage) v1beta1.PipelinePhase {
+	switch stage {
+	case v1beta1.PipelineStageScan:
+		return v1beta1.PipelineScanning
+	case v1beta1.PipelineStageUpload:
+		return v1beta1.PipelineUploading
+	default:
+		return v1beta1.PipelineDownloading
+	}
+}
+
+// stagePodName returns the name of the pod running the stage for the current attempt
+// and re-upload of the pipeline.
+func stagePodName(pipeline *v1beta1.Pipeline, stage v1beta1.PipelineStage) string {
+	name := scanPodName(pipeline) + "-" + strings.ToLower(string(stage))
+	if stage == v1beta1.PipelineStageUpload && pipeline.Status.Reuploads > 0 {
+		// each re-upload creates a new upload pod, keeping the previous pods
+		name = fmt.Sprintf("%s-reupload-%d", name, pipeline.Status.Reuploads)
+	}
+	return name
+}
+
+// populateStagePod populates the pod for a single stage of a pipeline using
+// [v1beta1.PipelineExecutionModeMultiPod]. The pod is generated from the scan pod,
+// keeping only the containers for the stage and replacing the volumes shared between
+// stages with the pipeline's PersistentVolumeClaim.
+func (r *PipelineReconciler) populateStagePod(
+	pod *corev1.Pod,
+	stage v1beta1.PipelineStage,
+	claimName string,
+	pipeline *v1beta1.Pipeline,
+	profile resources.Invocation[v1beta1.ProfileSpec],
+	downloader resources.Invocation[v1beta1.DownloaderSpec],
+	uploaders []resources.Invocation[v1beta1.UploaderSpec],
+) error {
+	// only edit pod spec if not created yet
+	// since once created, spec cant really be modified
+	if pod.CreationTimestamp.IsZero() {
+		scanPod := &corev1.Pod{}
+		if err := r.populateScanPod(scanPod, pipeline, profile, downloader, uploaders); err != nil {
+			return err
+		}
+
+		spec := scanPod.Spec
+		isSidecarInit := func(c corev1.Container) bool { return c.Name == sidecarInitContainerName }
+		switch stage {
+		case v1beta1.PipelineStageDownload:
+			// the downloader no longer needs to run before any
+			// other container, so it is the only container of the pod
+			spec.Containers = filterContainers(spec.InitContainers, func(c corev1.Container) bool {
+				return strings.HasPrefix(c.Name, downloadContainerPrefix)
+			})
+			spec.InitContainers = nil
+		case v1beta1.PipelineStageScan:
+			spec.InitContainers = filterContainers(spec.InitContainers, isSidecarInit)
+			spec.Containers = filterContainers(spec.Containers, func(c corev1.Container) bool {
+				return strings.HasPrefix(c.Name, scanContainerPrefix)
+			})
+		case v1beta1.PipelineStageUpload:
+			spec.InitContainers = filterContainers(spec.InitContainers, isSidecarInit)
+			spec.Containers = filterContainers(spec.Containers, func(c corev1.Container) bool {
+				return strings.HasPrefix(c.Name, uploadContainerPrefix)
+			})
+		}
+		if stage == v1beta1.PipelineStageDownload {
+			// the downloader is the only container of the pod, so the
+			// kubelet can enforce its deadline through the pod
+			spec.ActiveDeadlineSeconds = downloader.Spec.ActiveDeadlineSeconds
+		}
+		mountPipelineStorage(&spec, claimName)
+		if spec.SecurityContext == nil {
+			spec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		if spec.SecurityContext.FSGroup == nil {
+			spec.SecurityContext.FSGroup = new(claimFSGroup)
+		}
+
+		pod.Spec = spec
+		pod.Labels = scanPod.Labels
+		pod.Labels[v1beta1.PipelineStageLabelKey] = string(stage)
+		pod.Annotations = scanPod.Annotations
+	}
+
+	return ctrl.SetControllerReference(pipeline, pod, r.Scheme)
+}
+
+// populateStorageClaim populates the PersistentVolumeClaim shared
+// between the stage pods of a pipeline from [v1beta1.PipelineSpec.Storage].
+func (r *PipelineReconciler) populateStorageClaim(claim *corev1.PersistentVolumeClaim, pipeline *v1beta1.Pipeline) error {
+	// only edit the claim spec if not created yet
+	// since once created, most of the spec is immutable
+	if claim.CreationTimestamp.IsZero() {
+		storage := pipeline.Spec.Storage
+		if storage == nil {
+			storage = &v1beta1.PipelineStorage{}
+		}
+
+		size := defaultPipelineStorageSize
+		if storage.Size != nil {
+			size = *storage.Size
+		}
+		accessModes := storage.AccessModes
+		if len(accessModes) == 0 {
+			accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+		}
+
+		claim.Spec = corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: storage.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		}
+
+		if claim.Labels == nil {
+			claim.Labels = make(map[string]string)
+		}
+		claim.Labels[v1beta1.PipelineLabelKey] = pipeline.GetName()
+	}
+
+	return ctrl.SetControllerReference(pipeline, claim, r.Scheme)
+}
+
+// mountPipelineStorage replaces the volumes shared between stages in the pod spec
+// with sub paths of the pipeline's PersistentVolumeClaim. Volumes which are
+// no longer mounted by any container of the pod are removed.
+func mountPipelineStorage(spec *corev1.PodSpec, claimName string) {
+	mounted := make(map[string]bool)
+	for _, cs := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range cs {
+			mounts := slices.Clone(cs[i].VolumeMounts)
+			for j, mount := range mounts {
+				if subPath, ok := pipelineStorageSubPaths[mount.Name]; ok {
+					mounts[j].Name = pipelineStorageVolumeName
+					mounts[j].SubPath = subPath
+				}
+				mounted[mounts[j].Name] = true
+			}
+			cs[i].VolumeMounts = mounts
+		}
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: pipelineStorageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: claimName,
+				},
+			},
+		},
+	}
+	for _, v := range spec.Volumes {
+		if mounted[v.Name] && v.Name != pipelineStorageVolumeName {
+			volumes = append(volumes, v)
+		}
+	}
+	spec.Volumes = volumes
+}
+
+// filterContainers returns the containers for which keep returns true.
+func filterContainers(cs []corev1.Container, keep func(corev1.Container) bool) []corev1.Container {
+	var filtered []corev1.Container
+	for _, c := range cs {
+		if keep(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
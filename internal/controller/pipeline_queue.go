@@ -0,0 +1,273 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// pipelineQueuedRequeueInterval is the interval at which queued pipelines
+	// re-check admission. Queued pipelines are also re-checked when any
+	// pipeline finishes or a queue changes, so this only acts as a fallback.
+	pipelineQueuedRequeueInterval = 30 * time.Second
+
+	// pipelineAdmissionTTL is how long an admitted pipeline is counted as active
+	// while its start time is not yet in the cache.
+	pipelineAdmissionTTL = time.Minute
+)
+
+// pipelineAdmissions records the pipelines admitted by the controller. Admission
+// is checked against the cache, which may not have the start time of a pipeline
+// admitted by a previous reconcile yet, so those pipelines are counted as active
+// to avoid admitting more pipelines than a queue allows.
+type pipelineAdmissions struct {
+	mu sync.Mutex
+	// admitted maps the UID of each admitted pipeline to the time it expires.
+	admitted map[types.UID]time.Time
+}
+
+// admit records that the pipeline was admitted.
+func (a *pipelineAdmissions) admit(pipeline *v1beta1.Pipeline, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.admitted == nil {
+		a.admitted = make(map[types.UID]time.Time)
+	}
+	maps.DeleteFunc(a.admitted, func(_ types.UID, expiry time.Time) bool {
+		return !now.Before(expiry)
+	})
+	a.admitted[pipeline.UID] = now.Add(pipelineAdmissionTTL)
+}
+
+// isAdmitted returns true if the pipeline, which has not started in the cache,
+// was admitted within the [pipelineAdmissionTTL].
+func (a *pipelineAdmissions) isAdmitted(pipeline *v1beta1.Pipeline, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	expiry, ok := a.admitted[pipeline.UID]
+	return ok && now.Before(expiry)
+}
+
+// pipelineQueue is either a [v1beta1.PipelineQueue] or a [v1beta1.ClusterPipelineQueue]
+// that applies to a pipeline.
+type pipelineQueue struct {
+	kind string
+	// namespace is the namespace the queue applies to,
+	// empty if the queue applies to all namespaces.
+	namespace string
+	name      string
+	spec      v1beta1.PipelineQueueSpec
+}
+
+func (q pipelineQueue) String() string {
+	if q.namespace == "" {
+		return q.kind + "/" + q.name
+	}
+	return q.kind + "/" + q.namespace + "/" + q.name
+}
+
+// matches returns true if the pipeline is limited by the queue.
+func (q pipelineQueue) matches(pipeline *v1beta1.Pipeline) (bool, error) {
+	if q.namespace != "" && pipeline.Namespace != q.namespace {
+		return false, nil
+	}
+	if len(q.spec.Profiles) > 0 && !slices.Contains(q.spec.Profiles, pipeline.Spec.ProfileRef.Name) {
+		return false, nil
+	}
+	if len(q.spec.Downloaders) > 0 && !slices.Contains(q.spec.Downloaders, pipeline.Spec.DownloaderRef.Name) {
+		return false, nil
+	}
+	if q.spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(q.spec.Selector)
+		if err != nil {
+			return false, fmt.Errorf("invalid selector for %s: %w", q, err)
+		}
+		return selector.Matches(labels.Set(pipeline.Labels)), nil
+	}
+	return true, nil
+}
+
+// pipelineQueueAdmission is the result of checking
+// if a pipeline can be admitted by all of its queues.
+type pipelineQueueAdmission struct {
+	// Queue is the queue the pipeline is waiting on,
+	// nil if the pipeline can be admitted.
+	Queue *pipelineQueue
+	// Position is the position of the pipeline in Queue,
+	// where 1 is the next pipeline to be admitted.
+	Position int
+}
+
+// checkPipelineAdmission determines if the pipeline can create its scan pod,
+// given the [v1beta1.PipelineQueue] in its namespace and all [v1beta1.ClusterPipelineQueue].
+// A pipeline is admitted by a queue if the number of active pipelines (pipelines that have
+// started but not completed) plus the number of pending pipelines ahead of it is less than the
+// queue's limit. Pending pipelines are ordered by [lessPendingPipeline], so a higher
+// priority pipeline will take the next free slot over pipelines that have waited longer.
+// Pending pipelines recorded in admissions are counted as active, since a pipeline admitted
+// by a previous reconcile may not have its start time in the cache yet.
+func checkPipelineAdmission(ctx context.Context, c client.Client, admissions *pipelineAdmissions, pipeline *v1beta1.Pipeline) (pipelineQueueAdmission, error) {
+	queues, err := listPipelineQueues(ctx, c, pipeline.Namespace)
+	if err != nil {
+		return pipelineQueueAdmission{}, err
+	}
+
+	now := time.Now()
+	for _, queue := range queues {
+		matches, err := queue.matches(pipeline)
+		if err != nil {
+			return pipelineQueueAdmission{}, err
+		}
+		if !matches {
+			continue
+		}
+
+		var pipelineList v1beta1.PipelineList
+		if err = c.List(ctx, &pipelineList, client.InNamespace(queue.namespace)); err != nil {
+			return pipelineQueueAdmission{}, fmt.Errorf("unable to list pipelines for %s: %w", queue, err)
+		}
+
+		var (
+			active  int
+			pending []*v1beta1.Pipeline
+		)
+		for i := range pipelineList.Items {
+			p := &pipelineList.Items[i]
+			if p.UID == pipeline.UID || !p.DeletionTimestamp.IsZero() || p.Status.CompletionTime != nil {
+				continue
+			}
+			if ok, _ := queue.matches(p); !ok {
+				continue
+			}
+			if p.Status.StartTime != nil || admissions.isAdmitted(p, now) {
+				active++
+			} else {
+				pending = append(pending, p)
+			}
+		}
+
+		ahead := 0
+		for _, p := range pending {
+			if lessPendingPipeline(p, pipeline) {
+				ahead++
+			}
+		}
+
+		if active+ahead >= int(queue.spec.MaxActivePipelines) {
+			return pipelineQueueAdmission{
+				Queue:    &queue,
+				Position: active + ahead - int(queue.spec.MaxActivePipelines) + 1,
+			}, nil
+		}
+	}
+	return pipelineQueueAdmission{}, nil
+}
+
+// lessPendingPipeline orders pending pipelines in a queue,
+// returning true if a should be admitted before b.
+// Pipelines are ordered by [v1beta1.PipelineSpec.Priority],
+// then by their creation time.
+func lessPendingPipeline(a, b *v1beta1.Pipeline) bool {
+	if aPriority, bPriority := pipelinePriority(a), pipelinePriority(b); aPriority != bPriority {
+		return aPriority > bPriority
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+}
+
+// pipelinePriority returns the [v1beta1.PipelineSpec.Priority]
+// of the pipeline, defaulting to 0.
+func pipelinePriority(pipeline *v1beta1.Pipeline) int32 {
+	if pipeline.Spec.Priority == nil {
+		return 0
+	}
+	return *pipeline.Spec.Priority
+}
+
+// listPipelineQueues returns all [v1beta1.PipelineQueue] in the namespace
+// and all [v1beta1.ClusterPipelineQueue].
+func listPipelineQueues(ctx context.Context, c client.Client, namespace string) ([]pipelineQueue, error) {
+	var queueList v1beta1.PipelineQueueList
+	if err := c.List(ctx, &queueList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list pipeline queues: %w", err)
+	}
+	var clusterQueueList v1beta1.ClusterPipelineQueueList
+	if err := c.List(ctx, &clusterQueueList); err != nil {
+		return nil, fmt.Errorf("unable to list cluster pipeline queues: %w", err)
+	}
+
+	queues := make([]pipelineQueue, 0, len(queueList.Items)+len(clusterQueueList.Items))
+	for _, q := range queueList.Items {
+		queues = append(queues, pipelineQueue{
+			kind:      "PipelineQueue",
+			namespace: q.Namespace,
+			name:      q.Name,
+			spec:      q.Spec,
+		})
+	}
+	for _, q := range clusterQueueList.Items {
+		queues = append(queues, pipelineQueue{
+			kind: "ClusterPipelineQueue",
+			name: q.Name,
+			spec: q.Spec,
+		})
+	}
+	return queues, nil
+}
+
+// queuedPipelineRequests returns reconcile requests for every pipeline
+// that is currently waiting in a queue. It is used to re-check admission
+// of queued pipelines once a slot frees up.
+func queuedPipelineRequests(ctx context.Context, c client.Client) ([]reconcile.Request, error) {
+	var pipelineList v1beta1.PipelineList
+	if err := c.List(ctx, &pipelineList); err != nil {
+		return nil, err
+	}
+	var requests []reconcile.Request
+	for _, p := range pipelineList.Items {
+		if p.Status.StartTime == nil && meta.IsStatusConditionTrue(p.Status.Conditions, v1beta1.PipelineQueuedConditionType) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+		}
+	}
+	return requests, nil
+}
+
+// pipelineFinishedPredicate filters pipeline watch events to only
+// pipelines which have completed or been deleted, freeing a slot in any queue.
+var pipelineFinishedPredicate = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return true },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPipeline, ok1 := e.ObjectOld.(*v1beta1.Pipeline)
+		newPipeline, ok2 := e.ObjectNew.(*v1beta1.Pipeline)
+		if !ok1 || !ok2 {
+			return false
+		}
+		return oldPipeline.Status.CompletionTime == nil && newPipeline.Status.CompletionTime != nil
+	},
+}
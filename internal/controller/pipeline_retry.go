@@ -0,0 +1,127 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultPipelineRetryBackoff is the backoff used for the first retry
+	// when [v1beta1.PipelineRetryPolicy.BackoffSeconds] is not set.
+	defaultPipelineRetryBackoff = 10 * time.Second
+	// defaultPipelineRetryMaxBackoff is the maximum backoff used when
+	// [v1beta1.PipelineRetryPolicy.MaxBackoffSeconds] is not set.
+	defaultPipelineRetryMaxBackoff = 600 * time.Second
+)
+
+// scanPodName returns the name of the scan pod for the current attempt of the pipeline.
+// The first attempt uses the pipeline name, each retry after is suffixed
+// with the attempt number so that the failed pods are kept for debugging.
+func scanPodName(pipeline *v1beta1.Pipeline) string {
+	if pipeline.Status.Attempts <= 1 {
+		return pipelineResourcePrefix + pipeline.GetName()
+	}
+	return fmt.Sprintf("%s%s-%d", pipelineResourcePrefix, pipeline.GetName(), pipeline.Status.Attempts)
+}
+
+// failedPipelineStage returns the stage that caused the scan pod to fail.
+// If no stage reported a failure (e.g. the pod was evicted), the stage
+// that was in progress is returned. An empty stage is returned if neither
+// can be determined.
+func failedPipelineStage(statuses v1beta1.PipelineStageStatuses) v1beta1.PipelineStage {
+	stages := []struct {
+		stage  v1beta1.PipelineStage
+		status v1beta1.PipelineStageStatus
+	}{
+		{v1beta1.PipelineStageDownload, statuses.DownloadStatus},
+		{v1beta1.PipelineStageScan, statuses.ScanStatus},
+		{v1beta1.PipelineStageUpload, statuses.UploadStatus},
+	}
+	for _, s := range stages {
+		if s.status == v1beta1.PipelineStageFailed {
+			return s.stage
+		}
+	}
+	for _, s := range stages {
+		if s.status == v1beta1.PipelineStageInProgress {
+			return s.stage
+		}
+	}
+	return ""
+}
+
+// resetPipelineStages sets the status of the given stage, and every stage after it,
+// to [v1beta1.PipelineStageNotStarted].
+func resetPipelineStages(statuses *v1beta1.PipelineStageStatuses, from v1beta1.PipelineStage) {
+	switch from {
+	case v1beta1.PipelineStageDownload, "":
+		statuses.DownloadStatus = v1beta1.PipelineStageNotStarted
+		fallthrough
+	case v1beta1.PipelineStageScan:
+		statuses.ScanStatus = v1beta1.PipelineStageNotStarted
+		fallthrough
+	case v1beta1.PipelineStageUpload:
+		statuses.UploadStatus = v1beta1.PipelineStageNotStarted
+	}
+}
+
+// shouldRetryPipeline returns true if the retry policy of the pipeline
+// allows another attempt after a failure in the given stage.
+func shouldRetryPipeline(pipeline *v1beta1.Pipeline, stage v1beta1.PipelineStage) bool {
+	policy := pipeline.Spec.RetryPolicy
+	if policy == nil {
+		return false
+	}
+	// attempts includes the first run, which is not a retry
+	if max(pipeline.Status.Attempts, 1)-1 >= policy.MaxRetries {
+		return false
+	}
+	return len(policy.Stages) == 0 || slices.Contains(policy.Stages, stage)
+}
+
+// pipelineRetryBackoff returns the time to wait before the next attempt of the pipeline.
+// The backoff doubles for every retry, up to [v1beta1.PipelineRetryPolicy.MaxBackoffSeconds].
+func pipelineRetryBackoff(pipeline *v1beta1.Pipeline) time.Duration {
+	policy := pipeline.Spec.RetryPolicy
+	backoff, maxBackoff := defaultPipelineRetryBackoff, defaultPipelineRetryMaxBackoff
+	if policy.BackoffSeconds != nil {
+		backoff = time.Duration(*policy.BackoffSeconds) * time.Second
+	}
+	if policy.MaxBackoffSeconds != nil {
+		maxBackoff = time.Duration(*policy.MaxBackoffSeconds) * time.Second
+	}
+	for i := int32(1); i < pipeline.Status.Attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// scanPodFinishedAt returns the time the last container of the scan pod terminated,
+// falling back to the pod start time if no container has terminated.
+func scanPodFinishedAt(scanPod *corev1.Pod) time.Time {
+	var finishedAt time.Time
+	for _, cs := range slices.Concat(scanPod.Status.InitContainerStatuses, scanPod.Status.ContainerStatuses) {
+		if cs.State.Terminated != nil && cs.State.Terminated.FinishedAt.After(finishedAt) {
+			finishedAt = cs.State.Terminated.FinishedAt.Time
+		}
+	}
+	if finishedAt.IsZero() && scanPod.Status.StartTime != nil {
+		return scanPod.Status.StartTime.Time
+	}
+	if finishedAt.IsZero() {
+		return scanPod.CreationTimestamp.Time
+	}
+	return finishedAt
+}
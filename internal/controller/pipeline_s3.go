@@ -0,0 +1,61 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package controller
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// s3UploaderContainer returns the container for an uploader with [v1beta1.UploaderSpec.S3] set,
+// which runs the upload-s3 command of the sidecar with the configuration passed as environment
+// variables, and the credentials loaded from the referenced secret. The container is named
+// after the uploader, so that a profile can use more than one S3 uploader.
+func (r *PipelineReconciler) s3UploaderContainer(uploaderName string, spec *v1beta1.S3UploaderSpec) corev1.Container {
+	secretKeyRef := func(key string, optional bool) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: spec.CredentialsSecretRef,
+				Key:                  key,
+				Optional:             &optional,
+			},
+		}
+	}
+	return corev1.Container{
+		Name:            s3UploaderContainerName(uploaderName),
+		Image:           r.SidecarImage,
+		ImagePullPolicy: r.SidecarPullPolicy,
+		Command:         []string{sidecarBinaryPath, "upload-s3"},
+		Env: []corev1.EnvVar{
+			{Name: v1beta1.EnvVarS3Bucket, Value: spec.Bucket},
+			{Name: v1beta1.EnvVarS3Endpoint, Value: spec.Endpoint},
+			{Name: v1beta1.EnvVarS3Region, Value: spec.Region},
+			{Name: v1beta1.EnvVarS3ForcePathStyle, Value: strconv.FormatBool(spec.ForcePathStyle)},
+			{Name: v1beta1.EnvVarS3KeyTemplate, Value: spec.KeyTemplate},
+			{Name: v1beta1.EnvVarAWSAccessKeyID, ValueFrom: secretKeyRef(v1beta1.EnvVarAWSAccessKeyID, false)},
+			{Name: v1beta1.EnvVarAWSSecretAccessKey, ValueFrom: secretKeyRef(v1beta1.EnvVarAWSSecretAccessKey, false)},
+			{Name: v1beta1.EnvVarAWSSessionToken, ValueFrom: secretKeyRef(v1beta1.EnvVarAWSSessionToken, true)},
+		},
+	}
+}
+
+// s3UploaderContainerName returns the name of the container for the uploader, which
+// once prefixed with [uploadContainerPrefix] must be a valid DNS label. Uploader names
+// can contain dots, which are replaced, and are truncated to fit within the label.
+func s3UploaderContainerName(uploaderName string) string {
+	name := strings.ReplaceAll(uploaderName, ".", "-")
+	if maxLength := validation.DNS1123LabelMaxLength - len(uploadContainerPrefix); len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-")
+	}
+	return name
+}
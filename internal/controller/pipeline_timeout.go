@@ -0,0 +1,84 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podDeadlineExceededReason is the reason set by the kubelet on a pod
+// that has exceeded its [corev1.PodSpec.ActiveDeadlineSeconds].
+const podDeadlineExceededReason = "DeadlineExceeded"
+
+// timedOutPipelineStage returns the stage of the pipeline that exceeded its deadline in the pod,
+// or an empty stage if no deadline was exceeded. Scanners and uploaders have their deadline
+// enforced by the sidecar, which exits with [resources.TimedOutExitCode]. The downloader has
+// its deadline enforced by the kubelet, through the active deadline of the pod.
+func timedOutPipelineStage(pod *corev1.Pod) v1beta1.PipelineStage {
+	if pod == nil {
+		return ""
+	}
+	if pod.Status.Reason == podDeadlineExceededReason {
+		// the active deadline of a pod is only set for the downloader
+		return v1beta1.PipelineStageDownload
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode == resources.TimedOutExitCode {
+			if stage := containerPipelineStage(cs.Name); stage != v1beta1.PipelineStageDownload {
+				return stage
+			}
+		}
+	}
+	return ""
+}
+
+// downloaderDeadlineRemaining returns the time left before the downloader of the pod exceeds
+// its deadline, and false if the downloader is not running or has no deadline.
+func downloaderDeadlineRemaining(pod *corev1.Pod, deadlineSeconds *int64) (time.Duration, bool) {
+	if deadlineSeconds == nil {
+		return 0, false
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if strings.HasPrefix(cs.Name, downloadContainerPrefix) && cs.State.Running != nil {
+			deadline := cs.State.Running.StartedAt.Add(time.Duration(*deadlineSeconds) * time.Second)
+			return time.Until(deadline), true
+		}
+	}
+	return 0, false
+}
+
+// enforceDownloaderDeadline sets the active deadline of a created scan pod once its downloader
+// has exceeded its deadline, which causes the kubelet to stop the pod. The downloader runs as an
+// init container of the scan pod, so its deadline can't be set on the pod when it is created.
+func enforceDownloaderDeadline(pod *corev1.Pod, deadlineSeconds *int64) {
+	remaining, running := downloaderDeadlineRemaining(pod, deadlineSeconds)
+	if !running || remaining > 0 || pod.Spec.ActiveDeadlineSeconds != nil || pod.Status.StartTime == nil {
+		return
+	}
+	pod.Spec.ActiveDeadlineSeconds = new(max(int64(time.Since(pod.Status.StartTime.Time).Seconds()), 1))
+}
+
+// stageTimedOutCondition returns the completion condition for a pipeline that
+// failed because the given stage exceeded its deadline.
+func stageTimedOutCondition(stage v1beta1.PipelineStage, t metav1.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:               v1beta1.CompletedSuccessfullyConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             "StageTimedOut",
+		Message:            fmt.Sprintf("The pipeline %s stage exceeded its deadline.", strings.ToLower(string(stage))),
+		LastTransitionTime: t,
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"maps"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
@@ -22,16 +23,21 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var (
@@ -76,13 +82,53 @@ func (r *SearchReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.Pod{}).
 		Owns(&corev1.ServiceAccount{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&rbacv1.RoleBinding{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1beta1.Pipeline{},
+			handler.EnqueueRequestsFromMapFunc(scheduledBySearch),
+			builder.WithPredicates(scheduledChildChangedPredicate)).
+		Watches(&v1beta1.Search{},
+			handler.EnqueueRequestsFromMapFunc(scheduledBySearch),
+			builder.WithPredicates(scheduledChildChangedPredicate)).
 		Complete(r)
 }
 
+// scheduledBySearch maps a pipeline or search to the search that
+// scheduled it, from its [v1beta1.ScheduledByLabelKey] label.
+func scheduledBySearch(_ context.Context, obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()[v1beta1.ScheduledByLabelKey]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}}}
+}
+
+// scheduledChildChangedPredicate filters pipeline and search watch events to only children
+// which were created, completed or deleted, so the search that scheduled them updates its
+// counts and completes once its last child completes. Events for children created in quick
+// succession are coalesced by the work queue.
+var scheduledChildChangedPredicate = predicate.Funcs{
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return childCompletionTime(e.ObjectOld) == nil && childCompletionTime(e.ObjectNew) != nil
+	},
+}
+
+// childCompletionTime returns the completion time of a pipeline or search.
+func childCompletionTime(obj client.Object) *metav1.Time {
+	switch child := obj.(type) {
+	case *v1beta1.Pipeline:
+		return child.Status.CompletionTime
+	case *v1beta1.Search:
+		return child.Status.CompletionTime
+	default:
+		return nil
+	}
+}
+
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=searches,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=searches/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=searches/finalizers,verbs=update
 // +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=crawlers;clustercrawlers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ocular.crashoverride.run,resources=pipelines,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts;pods,verbs=watch;create;get;list;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=watch;create;get;list;update;patch;delete
 
@@ -100,6 +146,8 @@ func (r *SearchReconciler) SetupWithManager(mgr ctrl.Manager) error {
 // 5. Fetch or create role binding (exit if created)
 // 6. Fetch or create pod (exit if created)
 // 7. Continually Update the search status accordingly based on the state of the pod
+// 8. Update the counts of scheduled children as they are created and complete,
+// completing the search once the pod and all children have completed
 // 9. Once completed, await TTL if set
 // For more details, check Reconcile and its Result here:
 // https://pkg.go.dev/sigs.k8s.io/controller-runtime/pkg/reconcile#Reconciler
@@ -288,9 +336,9 @@ func (r *SearchReconciler) populateSearchPod(search *v1beta1.Search, pod *corev1
 		var crawlerContainer corev1.Container
 		crawler.Spec.Container.DeepCopyInto(&crawlerContainer)
 
-		pipelineTemplateJSON, err := json.Marshal(search.Spec.Scheduler.PipelineTemplate)
+		pipelineTemplateJSON, err := json.Marshal(resources.SchedulerPipelineTemplatesForSearch(search.Spec.Scheduler))
 		if err != nil {
-			return fmt.Errorf("unable to marshal pipeline template: %w", err)
+			return fmt.Errorf("unable to marshal pipeline templates: %w", err)
 		}
 
 		if pod.Labels == nil {
@@ -341,10 +389,37 @@ func (r *SearchReconciler) populateSearchPod(search *v1beta1.Search, pod *corev1
 			),
 		)
 
+		volumes := append(crawler.Spec.Volumes, templateVolume, fifoVolume, binaryVolume)
+		if claimName := search.Spec.CrawlerStateClaimName; claimName != "" {
+			stateVolume := corev1.Volume{
+				Name: crawlerStateVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+				},
+			}
+			volumes = append(volumes, stateVolume)
+			if pod.Spec.SecurityContext == nil {
+				pod.Spec.SecurityContext = &corev1.PodSecurityContext{}
+			}
+			if pod.Spec.SecurityContext.FSGroup == nil {
+				pod.Spec.SecurityContext.FSGroup = new(claimFSGroup)
+			}
+			crawlerOptions = append(crawlerOptions,
+				containers.WithAdditionalVolumeMounts(corev1.VolumeMount{
+					Name:      stateVolume.Name,
+					MountPath: crawlerStateDirectory,
+				}),
+				containers.WithAdditionalEnvVars(corev1.EnvVar{
+					Name:  v1beta1.EnvVarCrawlerStateDir,
+					Value: crawlerStateDirectory,
+				}),
+			)
+		}
+
 		pod.Spec.Containers = containers.ApplyStandardOptions([]corev1.Container{
 			containers.ApplyOptionsTo(crawlerContainer, crawlerOptions...)})
 
-		pod.Spec.Volumes = append(crawler.Spec.Volumes, templateVolume, fifoVolume, binaryVolume)
+		pod.Spec.Volumes = volumes
 		pod.Spec.Resources = search.Spec.Resources.DeepCopy()
 		pod.Spec.ImagePullSecrets = crawler.Spec.ImagePullSecrets
 	}
@@ -354,12 +429,27 @@ func (r *SearchReconciler) populateSearchPod(search *v1beta1.Search, pod *corev1
 func (r *SearchReconciler) handleCompletion(ctx context.Context, search *v1beta1.Search, pod *corev1.Pod) (ctrl.Result, error) {
 	l := logf.FromContext(ctx)
 
-	childPipelines, childSearches, err := r.retrieveRunningScheduledResources(ctx, search)
+	childPipelines, childSearches, counted, err := r.countScheduledResources(ctx, search)
 	if err != nil {
 		return ctrl.Result{}, err
-	} else if len(childPipelines) > 0 || len(childSearches) > 0 {
-		l.Info("child resources not completed", "search", search.Name, "pipelines", childPipelines, "searches", childSearches)
-		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	setSchedulerCounts(&childPipelines, &childSearches, pod)
+	if search.Status.Pipelines != childPipelines || search.Status.Searches != childSearches {
+		search.Status.Pipelines = childPipelines
+		search.Status.Searches = childSearches
+		if err = updateStatus(ctx, r.Client, search, "step", "scheduled resource counts"); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	// the children are only marked once their outcome is in the status, so a
+	// failed update is retried with the children still counted as not recorded
+	if err = r.markScheduledResourcesCounted(ctx, search, counted); err != nil {
+		return ctrl.Result{}, err
+	}
+	if childPipelines.Running > 0 || childSearches.Running > 0 {
+		// the search is reconciled again once a child completes
+		l.Info("child resources not completed", "search", search.Name, "pipelines", childPipelines.Running, "searches", childSearches.Running)
+		return ctrl.Result{}, nil
 	}
 
 	metricLabels := prometheus.Labels{"namespace": search.Namespace, "crawler": search.Spec.CrawlerRef.Name}
@@ -382,13 +472,22 @@ func (r *SearchReconciler) handleCompletion(ctx context.Context, search *v1beta1
 		if search.Status.CompletionTime == nil {
 			t := metav1.NewTime(time.Now())
 			search.Status.CompletionTime = new(t)
-			search.Status.Conditions = append(search.Status.Conditions, metav1.Condition{
+			condition := metav1.Condition{
 				Type:               v1beta1.CompletedSuccessfullyConditionType,
 				Status:             metav1.ConditionTrue,
 				Reason:             "SearchPodSucceeded",
 				Message:            "Search completed successfully.",
 				LastTransitionTime: t,
-			})
+			}
+			if !resources.ChildrenAllowSearchSuccess(search.Spec.SuccessPolicy, childPipelines, childSearches) {
+				l.Info("search children failed, marking search as failed", "name", search.GetName(),
+					"policy", search.Spec.SuccessPolicy.Policy, "pipelines", childPipelines, "searches", childSearches)
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = "SearchChildrenFailed"
+				condition.Message = fmt.Sprintf("%d pipelines and %d searches failed or were not scheduled, which is not allowed by the success policy %s.",
+					childPipelines.Failed+childPipelines.FailedToSchedule, childSearches.Failed+childSearches.FailedToSchedule, search.Spec.SuccessPolicy.Policy)
+			}
+			search.Status.Conditions = append(search.Status.Conditions, condition)
 		}
 	case corev1.PodPending, corev1.PodRunning:
 		l.Info("search pod is still running", "name", search.GetName(), "pod", pod.GetName(), "phase", pod.Status.Phase)
@@ -409,13 +508,21 @@ func (r *SearchReconciler) handleCompletion(ctx context.Context, search *v1beta1
 	return ctrl.Result{}, nil
 }
 
-func (r *SearchReconciler) retrieveRunningScheduledResources(ctx context.Context, search *v1beta1.Search) ([]v1beta1.Pipeline, []v1beta1.Search, error) {
+// countScheduledResources lists the pipelines and searches scheduled by the search and adds
+// the outcome of each completed child that has not been counted to the counts in the status
+// of the search, so the counts are kept once the child is deleted. The children counted are
+// returned, to be marked with [v1beta1.CountedBySearchAnnotation] once the status is updated.
+func (r *SearchReconciler) countScheduledResources(ctx context.Context, search *v1beta1.Search) (v1beta1.SearchChildrenStatus, v1beta1.SearchChildrenStatus, []client.Object, error) {
 	var (
 		err error
 
 		pipelineList v1beta1.PipelineList
 		searchList   v1beta1.SearchList
+
+		pipelines, searches = search.Status.Pipelines, search.Status.Searches
+		counted             []client.Object
 	)
+	pipelines.Running, searches.Running = 0, 0
 	err = r.List(ctx, &pipelineList, &client.ListOptions{
 		Namespace: search.Namespace,
 		LabelSelector: labels.SelectorFromSet(labels.Set{
@@ -423,7 +530,7 @@ func (r *SearchReconciler) retrieveRunningScheduledResources(ctx context.Context
 		}),
 	})
 	if err != nil {
-		return nil, nil, err
+		return pipelines, searches, nil, err
 	}
 
 	err = r.List(ctx, &searchList, &client.ListOptions{
@@ -433,24 +540,84 @@ func (r *SearchReconciler) retrieveRunningScheduledResources(ctx context.Context
 		}),
 	})
 	if err != nil {
-		return nil, nil, err
+		return pipelines, searches, nil, err
 	}
 
-	pipelines := make([]v1beta1.Pipeline, 0, len(pipelineList.Items))
-	for _, p := range pipelineList.Items {
-		if p.Status.CompletionTime == nil {
-			pipelines = append(pipelines, p)
+	for i := range pipelineList.Items {
+		p := &pipelineList.Items[i]
+		switch {
+		case p.Status.CompletionTime == nil:
+			pipelines.Running++
+			continue
+		case p.Annotations[v1beta1.CountedBySearchAnnotation] != "":
+			continue
+		case p.Status.Phase == v1beta1.PipelineSucceeded || p.Status.Phase == v1beta1.PipelinePartiallySucceeded:
+			pipelines.Succeeded++
+		default:
+			pipelines.Failed++
 		}
+		counted = append(counted, p)
 	}
 
-	searches := make([]v1beta1.Search, 0, len(searchList.Items))
-	for _, s := range searchList.Items {
-		if s.Status.CompletionTime == nil {
-			searches = append(searches, s)
+	for i := range searchList.Items {
+		s := &searchList.Items[i]
+		switch {
+		case s.Status.CompletionTime == nil:
+			searches.Running++
+			continue
+		case s.Annotations[v1beta1.CountedBySearchAnnotation] != "":
+			continue
+		case meta.IsStatusConditionTrue(s.Status.Conditions, v1beta1.CompletedSuccessfullyConditionType):
+			searches.Succeeded++
+		default:
+			searches.Failed++
 		}
+		counted = append(counted, s)
 	}
-	return pipelines, searches, nil
 
+	// until the scheduler reports the children it created,
+	// the children seen are the lower bound of those scheduled
+	pipelines.Scheduled = max(pipelines.Scheduled, pipelines.Running+pipelines.Succeeded+pipelines.Failed)
+	searches.Scheduled = max(searches.Scheduled, searches.Running+searches.Succeeded+searches.Failed)
+	return pipelines, searches, counted, nil
+}
+
+// markScheduledResourcesCounted sets the [v1beta1.CountedBySearchAnnotation] on each of the children
+// counted by [SearchReconciler.countScheduledResources], so their outcome is only counted once.
+func (r *SearchReconciler) markScheduledResourcesCounted(ctx context.Context, search *v1beta1.Search, counted []client.Object) error {
+	for _, child := range counted {
+		patch := client.MergeFrom(child.DeepCopyObject().(client.Object))
+		annotations := child.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[v1beta1.CountedBySearchAnnotation] = search.Name
+		child.SetAnnotations(annotations)
+		if err := patchResource(ctx, r.Client, child, patch); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to mark %s as counted: %w", child.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// setSchedulerCounts sets the number of pipelines and searches the scheduler created and failed to
+// create from the termination message written by the scheduler to the crawler container of the pod.
+func setSchedulerCounts(pipelines, searches *v1beta1.SearchChildrenStatus, pod *corev1.Pod) {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if !strings.HasPrefix(cs.Name, crawlerContainerPrefix) || cs.State.Terminated == nil || cs.State.Terminated.Message == "" {
+			continue
+		}
+		var message resources.SchedulerTerminationMessage
+		if err := json.Unmarshal([]byte(cs.State.Terminated.Message), &message); err != nil {
+			// the scheduler exited before writing the termination message
+			continue
+		}
+		pipelines.Scheduled = max(pipelines.Scheduled, message.CreatedPipelines)
+		pipelines.FailedToSchedule = message.FailedPipelines
+		searches.Scheduled = max(searches.Scheduled, message.CreatedSearches)
+		searches.FailedToSchedule = message.FailedSearches
+		return
+	}
 }
 
 func generateBaseSearchEnvironment(search *v1beta1.Search) []corev1.EnvVar {
@@ -458,6 +625,37 @@ func generateBaseSearchEnvironment(search *v1beta1.Search) []corev1.EnvVar {
 	if search.Spec.Scheduler.IntervalSeconds != nil {
 		schedulerInterval = int(*search.Spec.Scheduler.IntervalSeconds)
 	}
+	var maxActivePipelines, maxActiveSearches string
+	if search.Spec.Scheduler.MaxActivePipelines != nil {
+		maxActivePipelines = strconv.Itoa(int(*search.Spec.Scheduler.MaxActivePipelines))
+	}
+	if search.Spec.Scheduler.MaxActiveSearches != nil {
+		maxActiveSearches = strconv.Itoa(int(*search.Spec.Scheduler.MaxActiveSearches))
+	}
+	var (
+		deduplicationPolicy = v1beta1.NoDeduplication
+		deduplicationWindow string
+	)
+	if dedup := search.Spec.Scheduler.Deduplication; dedup != nil {
+		if dedup.Policy != "" {
+			deduplicationPolicy = dedup.Policy
+		}
+		if dedup.WindowSeconds != nil {
+			deduplicationWindow = strconv.Itoa(int(*dedup.WindowSeconds))
+		}
+	}
+	var overridePolicy string
+	if search.Spec.Scheduler.Overrides != nil {
+		// the policy only contains strings, so cannot fail to marshal
+		policyJSON, _ := json.Marshal(search.Spec.Scheduler.Overrides)
+		overridePolicy = string(policyJSON)
+	}
+	var successPolicy string
+	if search.Spec.SuccessPolicy != nil {
+		// the policy only contains a string and integer, so cannot fail to marshal
+		policyJSON, _ := json.Marshal(search.Spec.SuccessPolicy)
+		successPolicy = string(policyJSON)
+	}
 	return []corev1.EnvVar{
 		{
 			Name:  v1beta1.EnvVarSearchName,
@@ -487,10 +685,38 @@ func generateBaseSearchEnvironment(search *v1beta1.Search) []corev1.EnvVar {
 			Name:  v1beta1.EnvVarSearchFIFO,
 			Value: searchFIFOPath,
 		},
+		{
+			Name:  v1beta1.EnvVarSchedulerSocket,
+			Value: schedulerSocketPath,
+		},
 		{
 			Name:  v1beta1.EnvVarPipelineSchedulerIntervalSeconds,
 			Value: strconv.Itoa(schedulerInterval),
 		},
+		{
+			Name:  v1beta1.EnvVarPipelineSchedulerMaxActivePipelines,
+			Value: maxActivePipelines,
+		},
+		{
+			Name:  v1beta1.EnvVarPipelineSchedulerMaxActiveSearches,
+			Value: maxActiveSearches,
+		},
+		{
+			Name:  v1beta1.EnvVarPipelineDeduplicationPolicy,
+			Value: string(deduplicationPolicy),
+		},
+		{
+			Name:  v1beta1.EnvVarPipelineDeduplicationWindowSeconds,
+			Value: deduplicationWindow,
+		},
+		{
+			Name:  v1beta1.EnvVarPipelineOverridePolicy,
+			Value: overridePolicy,
+		},
+		{
+			Name:  v1beta1.EnvVarSearchSuccessPolicy,
+			Value: successPolicy,
+		},
 		{
 			Name:  v1beta1.EnvVarSchedulerParentUID,
 			Value: string(search.UID),
@@ -510,6 +736,16 @@ func (r *SearchReconciler) handlePostCompletion(ctx context.Context, search *v1b
 		ttl := time.Duration(*search.Spec.TTLSecondsAfterFinished) * time.Second
 		deleteTime := finishTime.Add(ttl)
 		if time.Now().After(deleteTime) {
+			awaiting, err := awaitingSearchCount(ctx, r.Client, search)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if awaiting {
+				// the search is reconciled again once the search that scheduled it marks it as counted
+				l.Info("search has exceeded its TTL, waiting for the search that scheduled it to count it",
+					"search", search.Labels[v1beta1.ScheduledByLabelKey])
+				return ctrl.Result{}, nil
+			}
 			l.Info("search has exceeded its TTL, deleting",
 				"completionTime", search.Status.CompletionTime, "ttlSecondsAfterFinished", *search.Spec.TTLSecondsAfterFinished)
 			if err := r.Delete(ctx, search); err != nil {
@@ -21,11 +21,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/resources"
 )
 
 var _ = Describe("Search Controller", func() {
@@ -366,25 +368,199 @@ var _ = Describe("Search Controller", func() {
 
 			// run once more to check handleCompletion doesn't mark
 			// as complete till pipeline is marked
-			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero(), "search should wait on its child watch instead of polling")
 			Expect(k8sClient.Get(ctx, typeNamespacedName, search)).Error().ToNot(HaveOccurred())
 			Expect(search.Status.StartTime).ToNot(BeNil())
 			Expect(search.Status.CompletionTime).To(BeNil())
+			Expect(search.Status.Pipelines).To(Equal(v1beta1.SearchChildrenStatus{Scheduled: 1, Running: 1}))
 
 			By("Updating the child resource to complete")
+			oldPipeline := pipeline.DeepCopy()
 			pipeline.Status.CompletionTime = new(metav1.NewTime(time.Now()))
+			pipeline.Status.Phase = v1beta1.PipelineSucceeded
 			Expect(k8sClient.Status().Update(ctx, pipeline)).Error().ToNot(HaveOccurred())
 
-			Expect(controllerReconciler.Reconcile(ctx, reconcile.Request{
-				NamespacedName: typeNamespacedName,
-			})).Error().NotTo(HaveOccurred())
+			By("Enqueuing the search from the child completion")
+			Expect(scheduledChildChangedPredicate.Update(event.UpdateEvent{
+				ObjectOld: oldPipeline,
+				ObjectNew: pipeline,
+			})).To(BeTrue())
+			requests := scheduledBySearch(ctx, pipeline)
+			Expect(requests).To(Equal([]reconcile.Request{{NamespacedName: typeNamespacedName}}))
+
+			Expect(controllerReconciler.Reconcile(ctx, requests[0])).Error().NotTo(HaveOccurred())
 			Expect(k8sClient.Get(ctx, typeNamespacedName, search)).Error().ToNot(HaveOccurred())
 			Expect(search.Status.StartTime).ToNot(BeNil())
 			Expect(search.Status.CompletionTime).ToNot(BeNil())
+			Expect(search.Status.Pipelines).To(Equal(v1beta1.SearchChildrenStatus{Scheduled: 1, Succeeded: 1}))
+
+		})
+
+		It("Should keep the counts of children once they are deleted", func() {
+			controllerReconciler := &SearchReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SearchClusterRole: "test-search-cluster-role",
+				SidecarImage:      "ocular-sidecar:test",
+				SidecarPullPolicy: corev1.PullNever,
+			}
+			searchPodName := types.NamespacedName{Name: searchResourcePrefix + resourceName, Namespace: testNamespace}
+			// the pod of a previous spec is not garbage collected by envtest
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: searchPodName.Name, Namespace: searchPodName.Namespace},
+			}))).To(Succeed())
+			Expect(controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})).Error().NotTo(HaveOccurred())
+
+			By("Completing a child which is deleted by its TTL")
+			failedPipeline := &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "failed-child-pipeline",
+					Namespace: testNamespace,
+					Labels:    map[string]string{v1beta1.ScheduledByLabelKey: search.Name},
+				},
+				Spec: pipeline.Spec,
+			}
+			Expect(k8sClient.Create(ctx, failedPipeline)).To(Succeed())
+			failedPipeline.Status.CompletionTime = new(metav1.NewTime(time.Now()))
+			failedPipeline.Status.Phase = v1beta1.PipelineFailed
+			Expect(k8sClient.Status().Update(ctx, failedPipeline)).To(Succeed())
+
+			for range 2 {
+				Expect(controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})).Error().NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, search)).To(Succeed())
+			Expect(search.Status.Pipelines).To(Equal(v1beta1.SearchChildrenStatus{Scheduled: 2, Running: 1, Failed: 1}),
+				"the outcome of the child should only be counted once")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(failedPipeline), failedPipeline)).To(Succeed())
+			Expect(failedPipeline.Annotations).To(HaveKeyWithValue(v1beta1.CountedBySearchAnnotation, search.Name))
+			Expect(k8sClient.Delete(ctx, failedPipeline)).To(Succeed())
+
+			By("Completing the scheduler with the counts of the children it created")
+			searchPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, searchPodName, searchPod)).To(Succeed())
+			searchPod.Status.Phase = corev1.PodSucceeded
+			searchPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name: crawlerContainerPrefix + "crawler",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `{"createdPipelines":3,"failedPipelines":1}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, searchPod)).To(Succeed())
+			pipeline.Status.CompletionTime = new(metav1.NewTime(time.Now()))
+			pipeline.Status.Phase = v1beta1.PipelineSucceeded
+			Expect(k8sClient.Status().Update(ctx, pipeline)).To(Succeed())
+
+			Expect(controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})).Error().NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, search)).To(Succeed())
+			Expect(search.Status.CompletionTime).NotTo(BeNil())
+			Expect(search.Status.Pipelines).To(Equal(v1beta1.SearchChildrenStatus{Scheduled: 3, Succeeded: 1, Failed: 1, FailedToSchedule: 1}))
+			Expect(k8sClient.Delete(ctx, searchPod)).To(Succeed())
+		})
 
+		It("Should apply the success policy to children deleted by their TTL", func() {
+			controllerReconciler := &SearchReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				SearchClusterRole: "test-search-cluster-role",
+				SidecarImage:      "ocular-sidecar:test",
+				SidecarPullPolicy: corev1.PullNever,
+			}
+			searchPodName := types.NamespacedName{Name: searchResourcePrefix + resourceName, Namespace: testNamespace}
+			// the pod of a previous spec is not garbage collected by envtest
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: searchPodName.Name, Namespace: searchPodName.Namespace},
+			}))).To(Succeed())
+			search.Spec.SuccessPolicy = &v1beta1.SearchSuccessPolicy{Policy: v1beta1.AllChildrenSucceededPolicy}
+			Expect(k8sClient.Update(ctx, search)).To(Succeed())
+			Expect(controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})).Error().NotTo(HaveOccurred())
+
+			By("Keeping a failed child past its TTL until the search counts it")
+			failedPipeline := &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ttl-child-pipeline",
+					Namespace: testNamespace,
+					Labels:    map[string]string{v1beta1.ScheduledByLabelKey: search.Name},
+				},
+				Spec: pipeline.Spec,
+			}
+			Expect(k8sClient.Create(ctx, failedPipeline)).To(Succeed())
+			failedPipeline.Status.CompletionTime = new(metav1.NewTime(time.Now()))
+			failedPipeline.Status.Phase = v1beta1.PipelineFailed
+			Expect(k8sClient.Status().Update(ctx, failedPipeline)).To(Succeed())
+			Expect(awaitingSearchCount(ctx, k8sClient, failedPipeline)).To(BeTrue())
+
+			Expect(controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})).Error().NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(failedPipeline), failedPipeline)).To(Succeed())
+			Expect(awaitingSearchCount(ctx, k8sClient, failedPipeline)).To(BeFalse())
+			Expect(k8sClient.Delete(ctx, failedPipeline)).To(Succeed())
+
+			By("Failing the search once the crawler and remaining children complete")
+			searchPod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, searchPodName, searchPod)).To(Succeed())
+			searchPod.Status.Phase = corev1.PodSucceeded
+			searchPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name: crawlerContainerPrefix + "crawler",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `{"createdPipelines":2}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, searchPod)).To(Succeed())
+			pipeline.Status.CompletionTime = new(metav1.NewTime(time.Now()))
+			pipeline.Status.Phase = v1beta1.PipelineSucceeded
+			Expect(k8sClient.Status().Update(ctx, pipeline)).To(Succeed())
+
+			Expect(controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})).Error().NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, search)).To(Succeed())
+			Expect(search.Status.CompletionTime).NotTo(BeNil())
+			Expect(search.Status.Pipelines).To(Equal(v1beta1.SearchChildrenStatus{Scheduled: 2, Succeeded: 1, Failed: 1}))
+			Expect(search.Status.Conditions).To(ContainElement(SatisfyAll(
+				HaveField("Type", v1beta1.CompletedSuccessfullyConditionType),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", "SearchChildrenFailed"),
+			)))
+			Expect(k8sClient.Delete(ctx, searchPod)).To(Succeed())
+		})
+
+		It("Should only enqueue the scheduling search when a child is created, completes or is deleted", func() {
+			childSearch := &v1beta1.Search{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "child-search",
+					Namespace: testNamespace,
+					Labels: map[string]string{
+						v1beta1.ScheduledByLabelKey: resourceName,
+					},
+				},
+			}
+			Expect(scheduledBySearch(ctx, childSearch)).To(Equal([]reconcile.Request{{NamespacedName: typeNamespacedName}}))
+			Expect(scheduledBySearch(ctx, &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{Name: "unscheduled", Namespace: testNamespace},
+			})).To(BeEmpty())
+
+			Expect(scheduledChildChangedPredicate.Create(event.CreateEvent{Object: childSearch})).To(BeTrue())
+			Expect(scheduledChildChangedPredicate.Delete(event.DeleteEvent{Object: childSearch})).To(BeTrue())
+			Expect(scheduledChildChangedPredicate.Generic(event.GenericEvent{Object: childSearch})).To(BeFalse())
+
+			started := childSearch.DeepCopy()
+			started.Status.StartTime = new(metav1.NewTime(time.Now()))
+			Expect(scheduledChildChangedPredicate.Update(event.UpdateEvent{
+				ObjectOld: childSearch,
+				ObjectNew: started,
+			})).To(BeFalse(), "starting a child should not enqueue the search")
+
+			completed := started.DeepCopy()
+			completed.Status.CompletionTime = new(metav1.NewTime(time.Now()))
+			Expect(scheduledChildChangedPredicate.Update(event.UpdateEvent{
+				ObjectOld: started,
+				ObjectNew: completed,
+			})).To(BeTrue())
+			Expect(scheduledChildChangedPredicate.Update(event.UpdateEvent{
+				ObjectOld: completed,
+				ObjectNew: completed.DeepCopy(),
+			})).To(BeFalse(), "updates after completion should not enqueue the search")
 		})
 	})
 
@@ -563,7 +739,7 @@ func ValidateSearchPodSpec(
 	Expect(pod.Spec.RuntimeClassName).To(Equal(search.Spec.RuntimeClassName))
 
 	// check annotations
-	templateJSON, err := json.Marshal(search.Spec.Scheduler.PipelineTemplate)
+	templateJSON, err := json.Marshal(resources.SchedulerPipelineTemplatesForSearch(search.Spec.Scheduler))
 	Expect(err).NotTo(HaveOccurred())
 	Expect(pod.Annotations).
 		To(HaveKeyWithValue(v1beta1.PipelineTemplateAnnotation, string(templateJSON)))
@@ -587,6 +763,10 @@ func ValidateSearchPodSpec(
 			Name:  v1beta1.EnvVarPipelineFIFO,
 			Value: pipelineFIFOPath,
 		},
+		corev1.EnvVar{
+			Name:  v1beta1.EnvVarSchedulerSocket,
+			Value: schedulerSocketPath,
+		},
 		corev1.EnvVar{
 			Name: "OCULAR_POD_NAME",
 			ValueFrom: &corev1.EnvVarSource{
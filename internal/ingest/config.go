@@ -0,0 +1,111 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+// Package ingest implements the optional HTTP receiver of the controller, which creates
+// pipelines from push events sent by a source forge or CI system, or from a [v1beta1.Target].
+package ingest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// Format is the format of the payloads accepted by a [Receiver].
+type Format string
+
+const (
+	// FormatTarget accepts a JSON encoded [v1beta1.Target].
+	FormatTarget Format = "Target"
+	// FormatGitHub accepts GitHub push events, using the URL of the
+	// repository as the target identifier and the pushed commit as the version.
+	FormatGitHub Format = "GitHub"
+	// FormatGitLab accepts GitLab push events, using the URL of the
+	// project as the target identifier and the pushed commit as the version.
+	FormatGitLab Format = "GitLab"
+)
+
+// Config is the configuration file of the receiver, read by [LoadConfig].
+type Config struct {
+	// Receivers are the receivers served, each at the path "/receivers/<name>".
+	Receivers []Receiver `json:"receivers"`
+}
+
+// Receiver creates a pipeline from its [Receiver.PipelineTemplate]
+// for each payload received with a valid signature.
+type Receiver struct {
+	// Name is the name of the receiver, used in its path.
+	Name string `json:"name"`
+	// Namespace is the namespace pipelines are created in.
+	Namespace string `json:"namespace"`
+	// Format is the format of the payloads accepted.
+	Format Format `json:"format"`
+	// SecretFile is the path of the file containing the secret payloads are signed with,
+	// such as a mounted Secret. It is read for every payload, so the secret can be rotated.
+	SecretFile string `json:"secretFile"`
+	// PipelineTemplate is the template of the pipelines created, with the received target set.
+	PipelineTemplate v1beta1.PipelineTemplate `json:"pipelineTemplate"`
+}
+
+// LoadConfig reads and validates the YAML or JSON configuration file at path.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("unable to read ingest config: %w", err)
+	}
+	if err = yaml.UnmarshalStrict(data, &config); err != nil {
+		return config, fmt.Errorf("unable to parse ingest config: %w", err)
+	}
+	return config, config.Validate()
+}
+
+// Validate returns an error for any receiver that is missing a field,
+// has an invalid name or format, or has the same name as another receiver.
+func (c Config) Validate() error {
+	var errs []error
+	names := make(map[string]bool, len(c.Receivers))
+	for i, r := range c.Receivers {
+		if msgs := validation.IsDNS1123Label(r.Name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("receivers[%d]: invalid name '%s': %v", i, r.Name, msgs))
+		} else if names[r.Name] {
+			errs = append(errs, fmt.Errorf("receivers[%d]: duplicate name '%s'", i, r.Name))
+		}
+		names[r.Name] = true
+		if r.Namespace == "" {
+			errs = append(errs, fmt.Errorf("receivers[%d]: namespace is required", i))
+		}
+		if r.SecretFile == "" {
+			errs = append(errs, fmt.Errorf("receivers[%d]: secretFile is required", i))
+		}
+		switch r.Format {
+		case FormatTarget, FormatGitHub, FormatGitLab:
+		default:
+			errs = append(errs, fmt.Errorf("receivers[%d]: unknown format '%s'", i, r.Format))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readSecret returns the secret of the receiver, without any trailing newline.
+func (r Receiver) readSecret() ([]byte, error) {
+	secret, err := os.ReadFile(r.SecretFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret: %w", err)
+	}
+	secret = bytes.TrimRight(secret, "\r\n")
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("secret file %s is empty", r.SecretFile)
+	}
+	return secret, nil
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package ingest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+const (
+	// SignatureHeader is the header containing the HMAC-SHA256 of the payload,
+	// hex encoded with the prefix "sha256=", as sent by GitHub.
+	SignatureHeader = "X-Hub-Signature-256"
+	// GitLabTokenHeader is the header containing the secret token sent by GitLab,
+	// which does not sign payloads. It is only accepted by [FormatGitLab] receivers.
+	GitLabTokenHeader = "X-Gitlab-Token"
+
+	githubEventHeader = "X-GitHub-Event"
+	gitlabEventHeader = "X-Gitlab-Event"
+
+	signaturePrefix = "sha256="
+	// nullCommit is the commit sent as the new commit of a push that deleted the ref.
+	nullCommit = "0000000000000000000000000000000000000000"
+)
+
+var errInvalidSignature = errors.New("missing or invalid signature")
+
+// Sign returns the value of the [SignatureHeader] for the payload signed with secret.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature checks the signature of the payload of the request, returning
+// [errInvalidSignature] if it was not signed with secret.
+func verifySignature(format Format, header http.Header, secret, payload []byte) error {
+	if format == FormatGitLab {
+		if token := header.Get(GitLabTokenHeader); token != "" {
+			if subtle.ConstantTimeCompare([]byte(token), secret) == 1 {
+				return nil
+			}
+			return errInvalidSignature
+		}
+	}
+	signature, ok := strings.CutPrefix(header.Get(SignatureHeader), signaturePrefix)
+	if !ok {
+		return errInvalidSignature
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return errInvalidSignature
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(expected, mac.Sum(nil)) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// payloadDigest returns the hex encoded SHA-256 of the payload, used to reject replays.
+// Delivery IDs sent by GitHub and GitLab are not covered by the signature, so a replayed
+// payload is identified by its contents whatever its headers.
+func payloadDigest(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// githubPushEvent is the subset of a GitHub push event used to create the target.
+type githubPushEvent struct {
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"repository"`
+}
+
+// gitlabPushEvent is the subset of a GitLab push event used to create the target.
+type gitlabPushEvent struct {
+	ObjectKind string `json:"object_kind"`
+	After      string `json:"after"`
+	Project    struct {
+		WebURL string `json:"web_url"`
+	} `json:"project"`
+}
+
+// parseTarget returns the target of the payload, or nil if the payload is an event
+// that should not create a pipeline, such as a ping or the deletion of a branch.
+func parseTarget(format Format, header http.Header, payload []byte) (*v1beta1.Target, error) {
+	switch format {
+	case FormatGitHub:
+		if event := header.Get(githubEventHeader); event != "push" {
+			return nil, nil
+		}
+		var event githubPushEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("unable to decode push event: %w", err)
+		}
+		if event.Deleted || event.After == nullCommit {
+			return nil, nil
+		}
+		return validTarget(v1beta1.Target{Identifier: event.Repository.HTMLURL, Version: event.After})
+	case FormatGitLab:
+		if event := header.Get(gitlabEventHeader); event != "" && event != "Push Hook" {
+			return nil, nil
+		}
+		var event gitlabPushEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("unable to decode push event: %w", err)
+		}
+		if event.ObjectKind != "push" || event.After == nullCommit {
+			return nil, nil
+		}
+		return validTarget(v1beta1.Target{Identifier: event.Project.WebURL, Version: event.After})
+	default:
+		var target v1beta1.Target
+		if err := json.Unmarshal(payload, &target); err != nil {
+			return nil, fmt.Errorf("unable to decode target: %w", err)
+		}
+		return validTarget(target)
+	}
+}
+
+func validTarget(target v1beta1.Target) (*v1beta1.Target, error) {
+	if target.Identifier == "" {
+		return nil, errors.New("target identifier is required")
+	}
+	return &target, nil
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/internal/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// maxPayloadSize is the largest payload accepted, matching the limit of GitHub.
+	maxPayloadSize = 25 << 20
+
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 30 * time.Second
+	shutdownTimeout   = 30 * time.Second
+
+	// replayWindow is how long a delivery is remembered, during which the
+	// same payload sent to the same receiver does not create another pipeline.
+	replayWindow = 10 * time.Minute
+)
+
+var log = logf.Log.WithName("ingest")
+
+// Server serves the receivers of the [Config] on [Server.Addr], creating a pipeline
+// for each payload received. It implements [sigs.k8s.io/controller-runtime/pkg/manager.Runnable]
+// so it can be added to the manager, and is served by every replica of the controller.
+type Server struct {
+	// Addr is the address to listen on.
+	Addr string
+	// Client is used to create pipelines.
+	Client client.Client
+	// Config is the configuration of the receivers.
+	Config Config
+
+	// deliveries are the deliveries received within the [replayWindow].
+	// They are only tracked per replica.
+	deliveries deliveryCache
+}
+
+// deliveryCache records the deliveries received by the server,
+// so a payload which is replayed does not create another pipeline.
+type deliveryCache struct {
+	mu sync.Mutex
+	// seen maps each delivery to the time it expires.
+	seen map[string]time.Time
+}
+
+// reserve records the delivery, returning false if it was already
+// received within the [replayWindow].
+func (c *deliveryCache) reserve(key string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen == nil {
+		c.seen = make(map[string]time.Time)
+	}
+	if expiry, ok := c.seen[key]; ok && now.Before(expiry) {
+		return false
+	}
+	maps.DeleteFunc(c.seen, func(_ string, expiry time.Time) bool {
+		return !now.Before(expiry)
+	})
+	c.seen[key] = now.Add(replayWindow)
+	return true
+}
+
+// release forgets the delivery, so it can be retried
+// if no pipeline could be created for it.
+func (c *deliveryCache) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.seen, key)
+}
+
+// response is the body of every response of the server.
+type response struct {
+	// Pipeline is the name of the pipeline created.
+	Pipeline string `json:"pipeline,omitempty"`
+	// Message describes why no pipeline was created.
+	Message string `json:"message,omitempty"`
+}
+
+// Start serves the receivers until ctx is done.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("starting ingest server", "addr", s.Addr, "receivers", len(s.Config.Receivers))
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		log.Info("shutting down ingest server")
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// NeedLeaderElection returns false, since any replica can create pipelines.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the handler serving each receiver at "POST /receivers/<name>".
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, receiver := range s.Config.Receivers {
+		mux.HandleFunc("POST /receivers/"+receiver.Name, func(w http.ResponseWriter, r *http.Request) {
+			s.receive(w, r, receiver)
+		})
+	}
+	return mux
+}
+
+// receive verifies the signature of the payload and creates the pipeline for its target,
+// unless the payload is a replay of a delivery already received.
+func (s *Server) receive(w http.ResponseWriter, r *http.Request, receiver Receiver) {
+	l := log.WithValues("receiver", receiver.Name, "namespace", receiver.Namespace)
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeResponse(w, http.StatusRequestEntityTooLarge, response{Message: "payload too large"})
+			return
+		}
+		l.Error(err, "unable to read payload")
+		writeResponse(w, http.StatusBadRequest, response{Message: "unable to read payload"})
+		return
+	}
+
+	secret, err := receiver.readSecret()
+	if err != nil {
+		l.Error(err, "unable to read secret of receiver")
+		writeResponse(w, http.StatusInternalServerError, response{Message: "unable to verify signature"})
+		return
+	}
+	if err = verifySignature(receiver.Format, r.Header, secret, payload); err != nil {
+		l.Info("rejecting payload with invalid signature", "remote", r.RemoteAddr)
+		writeResponse(w, http.StatusUnauthorized, response{Message: err.Error()})
+		return
+	}
+
+	target, err := parseTarget(receiver.Format, r.Header, payload)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, response{Message: err.Error()})
+		return
+	} else if target == nil {
+		writeResponse(w, http.StatusAccepted, response{Message: "event ignored"})
+		return
+	}
+
+	delivery := receiver.Name + "/" + payloadDigest(payload)
+	if !s.deliveries.reserve(delivery, time.Now()) {
+		// accepted rather than rejected with an error, since senders
+		// such as GitLab disable webhooks which repeatedly fail
+		l.Info("ignoring replayed delivery", "delivery", delivery, "remote", r.RemoteAddr)
+		writeResponse(w, http.StatusAccepted, response{Message: "delivery already received"})
+		return
+	}
+
+	pipeline := newPipeline(receiver, *target)
+	if err = s.Client.Create(r.Context(), pipeline); err != nil {
+		s.deliveries.release(delivery)
+		l.Error(err, "unable to create pipeline for target", "target", target)
+		status := http.StatusInternalServerError
+		var apiStatus apierrors.APIStatus
+		if errors.As(err, &apiStatus) && apiStatus.Status().Code >= 400 && apiStatus.Status().Code < 500 {
+			status = int(apiStatus.Status().Code)
+		}
+		writeResponse(w, status, response{Message: err.Error()})
+		return
+	}
+	l.Info("pipeline created for target", "pipeline", pipeline.Name, "target", target)
+	writeResponse(w, http.StatusCreated, response{Pipeline: pipeline.Name})
+}
+
+// newPipeline creates the pipeline for the target from the template of the receiver.
+func newPipeline(receiver Receiver, target v1beta1.Target) *v1beta1.Pipeline {
+	template := receiver.PipelineTemplate
+	pipeline := &v1beta1.Pipeline{}
+	pipeline.GenerateName = template.GenerateName
+	if pipeline.GenerateName == "" {
+		pipeline.GenerateName = receiver.Name + "-"
+	}
+	pipeline.Namespace = receiver.Namespace
+	pipeline.Annotations = maps.Clone(template.Annotations)
+	pipeline.Labels = utils.MergeMaps(template.Labels, map[string]string{v1beta1.ReceiverLabelKey: receiver.Name})
+	template.Spec.DeepCopyInto(&pipeline.Spec)
+	pipeline.Spec.Target = target
+	return pipeline
+}
+
+func writeResponse(w http.ResponseWriter, status int, body response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
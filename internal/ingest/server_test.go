@@ -0,0 +1,327 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testSecret    = "s3cr3t"
+	testNamespace = "scans"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, client.Client) {
+	t.Helper()
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte(testSecret+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	template := v1beta1.PipelineTemplate{}
+	template.Labels = map[string]string{"team": "web"}
+	template.Spec.ProfileRef.Name = "default"
+	template.Spec.DownloaderRef.Name = "git"
+
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	var receivers []Receiver
+	for _, format := range []Format{FormatTarget, FormatGitHub, FormatGitLab} {
+		receivers = append(receivers, Receiver{
+			Name:             strings.ToLower(string(format)),
+			Namespace:        testNamespace,
+			Format:           format,
+			SecretFile:       secretFile,
+			PipelineTemplate: template,
+		})
+	}
+	server := &Server{Client: c, Config: Config{Receivers: receivers}}
+	if err := server.Config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return ts, c
+}
+
+func TestServer(t *testing.T) {
+	ts, c := newTestServer(t)
+
+	tests := []struct {
+		name           string
+		receiver       string
+		payload        string
+		headers        map[string]string
+		unsigned       bool
+		expectedStatus int
+		expectedTarget v1beta1.Target
+	}{
+		{
+			name:           "target",
+			receiver:       "target",
+			payload:        `{"identifier": "https://github.com/crashappsec/ocular", "version": "v1.0.0"}`,
+			expectedStatus: http.StatusCreated,
+			expectedTarget: v1beta1.Target{Identifier: "https://github.com/crashappsec/ocular", Version: "v1.0.0"},
+		},
+		{
+			name:           "github push",
+			receiver:       "github",
+			payload:        `{"ref": "refs/heads/main", "after": "abc123", "repository": {"html_url": "https://github.com/crashappsec/chalk"}}`,
+			headers:        map[string]string{githubEventHeader: "push"},
+			expectedStatus: http.StatusCreated,
+			expectedTarget: v1beta1.Target{Identifier: "https://github.com/crashappsec/chalk", Version: "abc123"},
+		},
+		{
+			name:           "github ping",
+			receiver:       "github",
+			payload:        `{"zen": "Keep it logically awesome."}`,
+			headers:        map[string]string{githubEventHeader: "ping"},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "github deleted branch",
+			receiver:       "github",
+			payload:        `{"after": "` + nullCommit + `", "deleted": true, "repository": {"html_url": "https://github.com/crashappsec/chalk"}}`,
+			headers:        map[string]string{githubEventHeader: "push"},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "gitlab push with token",
+			receiver:       "gitlab",
+			payload:        `{"object_kind": "push", "after": "def456", "project": {"web_url": "https://gitlab.com/crashappsec/ocular"}}`,
+			headers:        map[string]string{gitlabEventHeader: "Push Hook", GitLabTokenHeader: testSecret},
+			unsigned:       true,
+			expectedStatus: http.StatusCreated,
+			expectedTarget: v1beta1.Target{Identifier: "https://gitlab.com/crashappsec/ocular", Version: "def456"},
+		},
+		{
+			name:           "gitlab invalid token",
+			receiver:       "gitlab",
+			payload:        `{"object_kind": "push", "after": "def456", "project": {"web_url": "https://gitlab.com/crashappsec/ocular"}}`,
+			headers:        map[string]string{GitLabTokenHeader: "wrong"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing signature",
+			receiver:       "target",
+			payload:        `{"identifier": "https://github.com/crashappsec/ocular"}`,
+			unsigned:       true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid signature",
+			receiver:       "target",
+			payload:        `{"identifier": "https://github.com/crashappsec/ocular"}`,
+			headers:        map[string]string{SignatureHeader: Sign([]byte("wrong"), []byte(`{"identifier": "https://github.com/crashappsec/ocular"}`))},
+			unsigned:       true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "token not accepted for target",
+			receiver:       "target",
+			payload:        `{"identifier": "https://github.com/crashappsec/ocular"}`,
+			headers:        map[string]string{GitLabTokenHeader: testSecret},
+			unsigned:       true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing identifier",
+			receiver:       "target",
+			payload:        `{"version": "v1.0.0"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown receiver",
+			receiver:       "bitbucket",
+			payload:        `{}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, ts.URL+"/receivers/"+tt.receiver, strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.unsigned {
+				req.Header.Set(SignatureHeader, Sign([]byte(testSecret), []byte(tt.payload)))
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var body response
+			if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			var pipeline v1beta1.Pipeline
+			if err = c.Get(t.Context(), client.ObjectKey{Namespace: testNamespace, Name: body.Pipeline}, &pipeline); err != nil {
+				t.Fatalf("expected pipeline %q to be created: %v", body.Pipeline, err)
+			}
+			if pipeline.Spec.Target != tt.expectedTarget {
+				t.Errorf("expected target %+v, got %+v", tt.expectedTarget, pipeline.Spec.Target)
+			}
+			if pipeline.Spec.ProfileRef.Name != "default" || pipeline.Labels["team"] != "web" {
+				t.Errorf("expected pipeline to be created from template, got %+v", pipeline)
+			}
+			if pipeline.Labels[v1beta1.ReceiverLabelKey] != tt.receiver {
+				t.Errorf("expected receiver label %q, got %v", tt.receiver, pipeline.Labels)
+			}
+		})
+	}
+}
+
+func TestServerReplay(t *testing.T) {
+	ts, c := newTestServer(t)
+
+	send := func(receiver, payload string, headers map[string]string) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, ts.URL+"/receivers/"+receiver, strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(SignatureHeader, Sign([]byte(testSecret), []byte(payload)))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	push := `{"after": "abc123", "repository": {"html_url": "https://github.com/crashappsec/chalk"}}`
+	first := map[string]string{githubEventHeader: "push", "X-GitHub-Delivery": "72d3162e-cc78-11e3-81ab-4c9367dc0958"}
+	redelivered := map[string]string{githubEventHeader: "push", "X-GitHub-Delivery": "9a1c5d3e-cc78-11e3-81ab-4c9367dc0958"}
+	pushed := `{"after": "def456", "repository": {"html_url": "https://github.com/crashappsec/chalk"}}`
+	target := `{"identifier": "https://github.com/crashappsec/ocular"}`
+
+	steps := []struct {
+		name           string
+		receiver       string
+		payload        string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{"github delivery", "github", push, first, http.StatusCreated},
+		{"github replayed delivery", "github", push, first, http.StatusAccepted},
+		{"github new delivery of same payload", "github", push, redelivered, http.StatusAccepted},
+		{"github delivery of another payload", "github", pushed, redelivered, http.StatusCreated},
+		{"target", "target", target, nil, http.StatusCreated},
+		{"target replayed payload", "target", target, nil, http.StatusAccepted},
+	}
+	for _, step := range steps {
+		if status := send(step.receiver, step.payload, step.headers); status != step.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", step.name, step.expectedStatus, status)
+		}
+	}
+
+	var pipelines v1beta1.PipelineList
+	if err := c.List(t.Context(), &pipelines); err != nil {
+		t.Fatal(err)
+	}
+	if len(pipelines.Items) != 3 {
+		t.Errorf("expected 3 pipelines to be created, got %d", len(pipelines.Items))
+	}
+}
+
+func TestDeliveryCache(t *testing.T) {
+	var cache deliveryCache
+	now := time.Now()
+	if !cache.reserve("github/1", now) {
+		t.Fatal("expected first delivery to be reserved")
+	}
+	if cache.reserve("github/1", now.Add(replayWindow/2)) {
+		t.Error("expected delivery within the replay window to be rejected")
+	}
+	if !cache.reserve("gitlab/1", now) {
+		t.Error("expected delivery of another receiver to be reserved")
+	}
+	if !cache.reserve("github/1", now.Add(replayWindow)) {
+		t.Error("expected delivery after the replay window to be reserved")
+	}
+	if len(cache.seen) != 1 {
+		t.Errorf("expected expired deliveries to be pruned, got %v", cache.seen)
+	}
+	cache.release("github/1")
+	if !cache.reserve("github/1", now.Add(replayWindow)) {
+		t.Error("expected released delivery to be reserved again")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	if err := os.WriteFile(valid, []byte(`
+receivers:
+  - name: github
+    namespace: scans
+    format: GitHub
+    secretFile: /etc/ocular/ingest/github
+    pipelineTemplate:
+      spec:
+        profileRef:
+          name: default
+        downloaderRef:
+          name: git
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Receivers) != 1 || config.Receivers[0].PipelineTemplate.Spec.ProfileRef.Name != "default" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err = os.WriteFile(invalid, []byte(`
+receivers:
+  - name: GitHub
+    format: Bitbucket
+  - name: GitHub
+    namespace: scans
+    format: GitHub
+    secretFile: /etc/ocular/ingest/github
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadConfig(invalid); err == nil {
+		t.Error("expected error for invalid config")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+// TimedOutExitCode is the exit code reported by the sidecar for a container
+// that was stopped for exceeding its deadline, matching the coreutils timeout command.
+const TimedOutExitCode = 124
+
+// UploaderTerminationMessage is written by the sidecar as the termination message
+// of each uploader container, and read by the controller to update the pipeline status.
+type UploaderTerminationMessage struct {
+	// Artifacts are the results of checking the artifacts passed to the uploader.
+	Artifacts []v1beta1.PipelineArtifactStatus `json:"artifacts,omitempty"`
+}
+
+// TargetHash computes the value of the [v1beta1.TargetHashLabelKey] label
+// for a pipeline spec. The hash is derived from the target, profile reference
+// and downloader reference (including parameters), so two pipelines with the same
+// hash will process the same target in the same way. References are defaulted before
+// hashing so the hash matches regardless of if the admission webhook has run.
+// The result is a valid label value.
+func TargetHash(spec v1beta1.PipelineSpec) string {
+	// json encoding of structs is deterministic, and
+	// since all fields are strings or slices, it cannot fail
+	data, _ := json.Marshal(struct {
+		Target        v1beta1.Target                            `json:"target"`
+		ProfileRef    v1beta1.ParameterizedLocalObjectReference `json:"profileRef"`
+		DownloaderRef v1beta1.ParameterizedLocalObjectReference `json:"downloaderRef"`
+	}{
+		Target:        spec.Target,
+		ProfileRef:    ReferenceDefaulter(spec.ProfileRef, "Profile"),
+		DownloaderRef: ReferenceDefaulter(spec.DownloaderRef, "Downloader"),
+	})
+	sum := sha256.Sum224(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ScannerFailuresAllowUpload returns true if the uploaders of a pipeline should run
+// given the [v1beta1.ScannerFailurePolicy] of its profile, the number of scanners
+// that exited with a non-zero exit code and the total number of scanners.
+// An empty policy is treated as [v1beta1.ScannerFailurePolicyUploadThenFail].
+func ScannerFailuresAllowUpload(policy v1beta1.ScannerFailurePolicy, failed, total int) bool {
+	if failed == 0 {
+		return true
+	}
+	switch policy {
+	case v1beta1.ScannerFailurePolicyAllMustSucceed:
+		return false
+	case v1beta1.ScannerFailurePolicyUploadOnPartialFailure:
+		return failed < total
+	default:
+		return true
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	validationutils "k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestTargetHash(t *testing.T) {
+	base := v1beta1.PipelineSpec{
+		Target:        v1beta1.Target{Identifier: "https://github.com/crashappsec/ocular", Version: "main"},
+		ProfileRef:    v1beta1.ParameterizedLocalObjectReference{Name: "profile"},
+		DownloaderRef: v1beta1.ParameterizedLocalObjectReference{Name: "downloader"},
+	}
+	hash := TargetHash(base)
+	if errs := validationutils.IsValidLabelValue(hash); len(errs) > 0 {
+		t.Fatalf("TargetHash() = %q is not a valid label value: %v", hash, errs)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*v1beta1.PipelineSpec)
+		equal  bool
+	}{
+		{
+			name:   "defaulted kinds",
+			modify: func(s *v1beta1.PipelineSpec) { s.ProfileRef.Kind, s.DownloaderRef.Kind = "Profile", "Downloader" },
+			equal:  true,
+		},
+		{
+			name:   "different service account",
+			modify: func(s *v1beta1.PipelineSpec) { s.ServiceAccountName = "other" },
+			equal:  true,
+		},
+		{
+			name:   "different version",
+			modify: func(s *v1beta1.PipelineSpec) { s.Target.Version = "v1.0.0" },
+			equal:  false,
+		},
+		{
+			name:   "different downloader kind",
+			modify: func(s *v1beta1.PipelineSpec) { s.DownloaderRef.Kind = "ClusterDownloader" },
+			equal:  false,
+		},
+		{
+			name: "different profile parameters",
+			modify: func(s *v1beta1.PipelineSpec) {
+				s.ProfileRef.Parameters = []v1beta1.ParameterSetting{{Name: "PARAM", Value: "1"}}
+			},
+			equal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := *base.DeepCopy()
+			tt.modify(&spec)
+			if got := TargetHash(spec); (got == hash) != tt.equal {
+				t.Errorf("TargetHash() = %q, base hash %q, expected equal: %v", got, hash, tt.equal)
+			}
+		})
+	}
+}
+
+func TestScannerFailuresAllowUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   v1beta1.ScannerFailurePolicy
+		failed   int
+		expected bool
+	}{
+		{name: "no failures", policy: v1beta1.ScannerFailurePolicyAllMustSucceed, failed: 0, expected: true},
+		{name: "upload then fail", policy: v1beta1.ScannerFailurePolicyUploadThenFail, failed: 3, expected: true},
+		{name: "all must succeed", policy: v1beta1.ScannerFailurePolicyAllMustSucceed, failed: 1, expected: false},
+		{name: "empty policy", policy: "", failed: 1, expected: true},
+		{name: "partial failure", policy: v1beta1.ScannerFailurePolicyUploadOnPartialFailure, failed: 1, expected: true},
+		{name: "all scanners failed", policy: v1beta1.ScannerFailurePolicyUploadOnPartialFailure, failed: 3, expected: false},
+		{name: "ignore failures", policy: v1beta1.ScannerFailurePolicyIgnoreScannerFailures, failed: 3, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScannerFailuresAllowUpload(tt.policy, tt.failed, 3); got != tt.expected {
+				t.Errorf("ScannerFailuresAllowUpload(%q, %d, 3) = %v, expected %v", tt.policy, tt.failed, got, tt.expected)
+			}
+		})
+	}
+}
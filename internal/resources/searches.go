@@ -0,0 +1,53 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package resources
+
+import "github.com/crashappsec/ocular/api/v1beta1"
+
+// SchedulerTerminationMessage is written by the scheduler as the termination message
+// of the crawler container, and read by the controller to update the search status.
+type SchedulerTerminationMessage struct {
+	// CreatedPipelines is the number of pipelines the scheduler created.
+	CreatedPipelines int32 `json:"createdPipelines,omitempty"`
+	// CreatedSearches is the number of searches the scheduler created.
+	CreatedSearches int32 `json:"createdSearches,omitempty"`
+	// FailedPipelines is the number of pipelines the scheduler was unable to create.
+	FailedPipelines int32 `json:"failedPipelines,omitempty"`
+	// FailedSearches is the number of searches the scheduler was unable to create.
+	FailedSearches int32 `json:"failedSearches,omitempty"`
+}
+
+// ChildrenAllowSearchSuccess returns true if the outcomes of the pipelines and searches
+// scheduled by a search allow it to succeed, given its [v1beta1.SearchSuccessPolicy].
+// Children the scheduler failed to create are counted as failed. The counts are those recorded
+// in the status of the search as each child completes, with the scheduled counts reported by
+// the scheduler, so children deleted since are included. A nil policy is treated as
+// [v1beta1.CrawlerSucceededPolicy].
+func ChildrenAllowSearchSuccess(policy *v1beta1.SearchSuccessPolicy, pipelines, searches v1beta1.SearchChildrenStatus) bool {
+	if policy == nil {
+		return true
+	}
+	failed := pipelines.Failed + pipelines.FailedToSchedule + searches.Failed + searches.FailedToSchedule
+	total := pipelines.Scheduled + pipelines.FailedToSchedule + searches.Scheduled + searches.FailedToSchedule
+	if failed == 0 {
+		return true
+	}
+	switch policy.Policy {
+	case v1beta1.AllChildrenSucceededPolicy:
+		return false
+	case v1beta1.FailureThresholdPolicy:
+		var maxFailedPercent int32
+		if policy.MaxFailedPercent != nil {
+			maxFailedPercent = *policy.MaxFailedPercent
+		}
+		return int64(failed)*100 <= int64(maxFailedPercent)*int64(total)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+func TestChildrenAllowSearchSuccess(t *testing.T) {
+	threshold := func(percent int32) *v1beta1.SearchSuccessPolicy {
+		return &v1beta1.SearchSuccessPolicy{Policy: v1beta1.FailureThresholdPolicy, MaxFailedPercent: &percent}
+	}
+	tests := []struct {
+		name      string
+		policy    *v1beta1.SearchSuccessPolicy
+		pipelines v1beta1.SearchChildrenStatus
+		searches  v1beta1.SearchChildrenStatus
+		expected  bool
+	}{
+		{
+			name:      "no policy",
+			pipelines: v1beta1.SearchChildrenStatus{Scheduled: 2, Failed: 2},
+			expected:  true,
+		},
+		{
+			name:      "crawler succeeded",
+			policy:    &v1beta1.SearchSuccessPolicy{Policy: v1beta1.CrawlerSucceededPolicy},
+			pipelines: v1beta1.SearchChildrenStatus{Scheduled: 2, Failed: 2},
+			expected:  true,
+		},
+		{
+			name:      "all children succeeded",
+			policy:    &v1beta1.SearchSuccessPolicy{Policy: v1beta1.AllChildrenSucceededPolicy},
+			pipelines: v1beta1.SearchChildrenStatus{Scheduled: 2, Succeeded: 2},
+			searches:  v1beta1.SearchChildrenStatus{Scheduled: 1, Succeeded: 1},
+			expected:  true,
+		},
+		{
+			name:     "child search failed",
+			policy:   &v1beta1.SearchSuccessPolicy{Policy: v1beta1.AllChildrenSucceededPolicy},
+			searches: v1beta1.SearchChildrenStatus{Scheduled: 1, Failed: 1},
+			expected: false,
+		},
+		{
+			name:      "failed to schedule",
+			policy:    &v1beta1.SearchSuccessPolicy{Policy: v1beta1.AllChildrenSucceededPolicy},
+			pipelines: v1beta1.SearchChildrenStatus{Scheduled: 1, Succeeded: 1, FailedToSchedule: 1},
+			expected:  false,
+		},
+		{
+			name:      "below threshold",
+			policy:    threshold(25),
+			pipelines: v1beta1.SearchChildrenStatus{Scheduled: 3, Succeeded: 3, FailedToSchedule: 1},
+			expected:  true,
+		},
+		{
+			name:      "above threshold",
+			policy:    threshold(25),
+			pipelines: v1beta1.SearchChildrenStatus{Scheduled: 4, Succeeded: 2, Failed: 2},
+			expected:  false,
+		},
+		{
+			name:      "threshold without percent",
+			policy:    &v1beta1.SearchSuccessPolicy{Policy: v1beta1.FailureThresholdPolicy},
+			pipelines: v1beta1.SearchChildrenStatus{Scheduled: 100, Succeeded: 99, Failed: 1},
+			expected:  false,
+		},
+		{
+			name:     "no children",
+			policy:   threshold(0),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChildrenAllowSearchSuccess(tt.policy, tt.pipelines, tt.searches); got != tt.expected {
+				t.Errorf("ChildrenAllowSearchSuccess() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
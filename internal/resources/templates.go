@@ -0,0 +1,70 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package resources
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+// SchedulerPipelineTemplates is written by the controller as the [v1beta1.PipelineTemplateAnnotation]
+// of each search pod, and read by the scheduler to create the pipelines for each target.
+type SchedulerPipelineTemplates struct {
+	// Template is the [v1beta1.SearchSchedulerSpec.PipelineTemplate] of the search.
+	Template v1beta1.PipelineTemplate `json:"template,omitzero"`
+	// Named is the [v1beta1.SearchSchedulerSpec.PipelineTemplates] of the search.
+	Named []v1beta1.NamedPipelineTemplate `json:"named,omitempty"`
+	// Defaults is the [v1beta1.SearchSchedulerSpec.DefaultPipelineTemplates] of the search.
+	Defaults []string `json:"defaults,omitempty"`
+}
+
+// SchedulerPipelineTemplatesForSearch returns the pipeline templates of the search.
+func SchedulerPipelineTemplatesForSearch(spec v1beta1.SearchSchedulerSpec) SchedulerPipelineTemplates {
+	return SchedulerPipelineTemplates{
+		Template: spec.PipelineTemplate,
+		Named:    spec.PipelineTemplates,
+		Defaults: spec.DefaultPipelineTemplates,
+	}
+}
+
+// ApplyTo sets the pipeline templates of a search scheduler spec.
+func (t SchedulerPipelineTemplates) ApplyTo(spec *v1beta1.SearchSchedulerSpec) {
+	t.Template.DeepCopyInto(&spec.PipelineTemplate)
+	spec.PipelineTemplates = nil
+	for _, named := range t.Named {
+		spec.PipelineTemplates = append(spec.PipelineTemplates, *named.DeepCopy())
+	}
+	spec.DefaultPipelineTemplates = slices.Clone(t.Defaults)
+}
+
+// Select returns the templates with the given names, in the order given, or the
+// default templates if no names are given. If there are no default templates, the
+// unnamed template is returned, with an empty name. An error is returned for any
+// name that is not the name of a template.
+func (t SchedulerPipelineTemplates) Select(names []string) ([]v1beta1.NamedPipelineTemplate, error) {
+	if len(names) == 0 {
+		names = t.Defaults
+	}
+	if len(names) == 0 {
+		return []v1beta1.NamedPipelineTemplate{{PipelineTemplate: t.Template}}, nil
+	}
+	selected := make([]v1beta1.NamedPipelineTemplate, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(t.Named, func(n v1beta1.NamedPipelineTemplate) bool { return n.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown pipeline template '%s'", name)
+		}
+		if !slices.ContainsFunc(selected, func(n v1beta1.NamedPipelineTemplate) bool { return n.Name == name }) {
+			selected = append(selected, t.Named[i])
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package resources
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+func TestSchedulerPipelineTemplatesSelect(t *testing.T) {
+	named := func(name string) v1beta1.NamedPipelineTemplate {
+		template := v1beta1.NamedPipelineTemplate{Name: name}
+		template.Spec.ProfileRef.Name = name
+		return template
+	}
+	unnamed := v1beta1.PipelineTemplate{}
+	unnamed.Spec.ProfileRef.Name = "unnamed"
+
+	tests := []struct {
+		name      string
+		templates SchedulerPipelineTemplates
+		selected  []string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:      "unnamed template",
+			templates: SchedulerPipelineTemplates{Template: unnamed, Named: []v1beta1.NamedPipelineTemplate{named("sast")}},
+			expected:  []string{"unnamed"},
+		},
+		{
+			name: "default templates",
+			templates: SchedulerPipelineTemplates{
+				Template: unnamed,
+				Named:    []v1beta1.NamedPipelineTemplate{named("sast"), named("sbom")},
+				Defaults: []string{"sbom", "sast"},
+			},
+			expected: []string{"sbom", "sast"},
+		},
+		{
+			name: "selected templates",
+			templates: SchedulerPipelineTemplates{
+				Named:    []v1beta1.NamedPipelineTemplate{named("sast"), named("sbom")},
+				Defaults: []string{"sbom"},
+			},
+			selected: []string{"sast", "sast"},
+			expected: []string{"sast"},
+		},
+		{
+			name:      "unknown template",
+			templates: SchedulerPipelineTemplates{Template: unnamed, Named: []v1beta1.NamedPipelineTemplate{named("sast")}},
+			selected:  []string{"sast", "secrets"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := tt.templates.Select(tt.selected)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", selected)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var profiles []string
+			for _, template := range selected {
+				profiles = append(profiles, template.Spec.ProfileRef.Name)
+			}
+			if !slices.Equal(profiles, tt.expected) {
+				t.Errorf("expected templates %v, got %v", tt.expected, profiles)
+			}
+		})
+	}
+}
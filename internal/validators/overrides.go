@@ -0,0 +1,119 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package validators
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidatePipelineOverridePolicy validates that the label and annotation keys of the
+// policy are valid and do not use the reserved prefix, and the kinds of the allowed references.
+func ValidatePipelineOverridePolicy(policy *v1beta1.PipelineOverridePolicy, fldPath *field.Path) field.ErrorList {
+	if policy == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateOverrideKeys(policy.Labels, fldPath.Child("labels"))...)
+	allErrs = append(allErrs, validateOverrideKeys(policy.Annotations, fldPath.Child("annotations"))...)
+	for i, ref := range policy.Profiles {
+		if ref.Kind != "" && ref.Kind != "Profile" {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("profiles").Index(i).Child("kind"), ref.Kind, []string{"Profile"}))
+		}
+	}
+	for i, ref := range policy.Downloaders {
+		if ref.Kind != "" && ref.Kind != "Downloader" && ref.Kind != "ClusterDownloader" {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("downloaders").Index(i).Child("kind"), ref.Kind, []string{"Downloader", "ClusterDownloader"}))
+		}
+	}
+	return allErrs
+}
+
+// ValidatePipelineOverrides validates the overrides sent by a crawler for a target against the
+// policy of the search. Overrides that are not allowed by the policy are returned as
+// [field.ErrorTypeForbidden], and labels or annotations that are not valid as [field.ErrorTypeInvalid].
+func ValidatePipelineOverrides(policy *v1beta1.PipelineOverridePolicy, overrides *runtime.PipelineOverrides, fldPath *field.Path) field.ErrorList {
+	if overrides == nil {
+		return nil
+	}
+	if policy == nil {
+		policy = &v1beta1.PipelineOverridePolicy{}
+	}
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateOverrideReference(overrides.ProfileRef, policy.Profiles, policy.ProfileParameters, "Profile", fldPath.Child("profileRef"))...)
+	allErrs = append(allErrs, validateOverrideReference(overrides.DownloaderRef, policy.Downloaders, policy.DownloaderParameters, "Downloader", fldPath.Child("downloaderRef"))...)
+	allErrs = append(allErrs, validateOverrideParameters(overrides.ProfileParameters, policy.ProfileParameters, fldPath.Child("profileParameters"))...)
+	allErrs = append(allErrs, validateOverrideParameters(overrides.DownloaderParameters, policy.DownloaderParameters, fldPath.Child("downloaderParameters"))...)
+
+	labelsPath := fldPath.Child("labels")
+	for key := range overrides.Labels {
+		if !slices.Contains(policy.Labels, key) {
+			allErrs = append(allErrs, field.Forbidden(labelsPath.Key(key), "label is not allowed by the override policy of the search"))
+		}
+	}
+	allErrs = append(allErrs, ValidateAdditionalLabels(overrides.Labels, labelsPath)...)
+
+	annotationsPath := fldPath.Child("annotations")
+	for key := range overrides.Annotations {
+		if !slices.Contains(policy.Annotations, key) {
+			allErrs = append(allErrs, field.Forbidden(annotationsPath.Key(key), "annotation is not allowed by the override policy of the search"))
+		}
+	}
+	allErrs = append(allErrs, ValidateAdditionalAnnotations(overrides.Annotations, annotationsPath)...)
+	return allErrs
+}
+
+func validateOverrideKeys(keys []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, key := range keys {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), key, msg))
+		}
+		if strings.HasPrefix(key, v1beta1.Group) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), key, fmt.Sprintf("keys cannot use reserved prefix: %s", v1beta1.Group)))
+		}
+	}
+	return allErrs
+}
+
+func validateOverrideReference(
+	ref *v1beta1.ParameterizedLocalObjectReference,
+	allowed []v1beta1.AllowedReference,
+	allowedParams []string,
+	defaultKind string,
+	fldPath *field.Path,
+) field.ErrorList {
+	if ref == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	if !slices.ContainsFunc(allowed, func(a v1beta1.AllowedReference) bool { return a.Allows(*ref, defaultKind) }) {
+		allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("%s '%s' is not allowed by the override policy of the search", strings.ToLower(defaultKind), ref.Name)))
+	}
+	allErrs = append(allErrs, validateOverrideParameters(ref.Parameters, allowedParams, fldPath.Child("parameters"))...)
+	return allErrs
+}
+
+func validateOverrideParameters(params []v1beta1.ParameterSetting, allowed []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, param := range params {
+		if !slices.Contains(allowed, param.Name) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i), fmt.Sprintf("parameter '%s' is not allowed by the override policy of the search", param.Name)))
+		} else if param.ValueFrom != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("valueFrom"), param.ValueFrom, "parameters set by crawlers cannot use valueFrom"))
+		}
+	}
+	return allErrs
+}
@@ -11,6 +11,7 @@ package validators
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/crashappsec/ocular/internal/resources"
@@ -62,6 +63,12 @@ func ValidateProfile(ctx context.Context, c client.Client, profile *v1beta1.Prof
 		}
 	}
 
+	for i, check := range profile.Spec.ArtifactChecks {
+		if !slices.Contains(profile.Spec.Artifacts, check.Path) {
+			fieldErrors = append(fieldErrors, field.Invalid(field.NewPath("spec").Child("artifactChecks").Index(i).Child("path"), check.Path, "must be one of spec.artifacts"))
+		}
+	}
+
 	for i, c := range profile.Spec.Containers {
 		fieldErrors = append(fieldErrors, ValidateContainerDefinition(ctx, field.NewPath("spec").Child("containers").Index(i), c.Container)...)
 	}
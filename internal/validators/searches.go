@@ -0,0 +1,28 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package validators
+
+import (
+	"slices"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateDefaultPipelineTemplates validates that each default pipeline
+// template of the scheduler is the name of one of its pipeline templates.
+func ValidateDefaultPipelineTemplates(spec v1beta1.SearchSchedulerSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, name := range spec.DefaultPipelineTemplates {
+		if !slices.ContainsFunc(spec.PipelineTemplates, func(t v1beta1.NamedPipelineTemplate) bool { return t.Name == name }) {
+			allErrs = append(allErrs, field.NotFound(fldPath.Index(i), name))
+		}
+	}
+	return allErrs
+}
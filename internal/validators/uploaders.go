@@ -0,0 +1,56 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package validators
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// S3KeyTemplatePlaceholders are the placeholders that can be used in [v1beta1.S3UploaderSpec.KeyTemplate].
+var S3KeyTemplatePlaceholders = []string{"namespace", "profile", "downloader", "uploader", "pipeline", "target", "version"}
+
+var s3KeyTemplatePlaceholderRegexp = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// ValidateUploaderSpec validates that exactly one of the container or S3 configuration
+// of the uploader is set, and that the one which is set is valid.
+func ValidateUploaderSpec(ctx context.Context, fieldPath *field.Path, spec v1beta1.UploaderSpec) field.ErrorList {
+	if spec.S3 == nil {
+		return ValidateContainerDefinition(ctx, fieldPath.Child("container"), spec.Container)
+	}
+
+	var fieldErrs field.ErrorList
+	if !equality.Semantic.DeepEqual(spec.Container, corev1.Container{}) {
+		fieldErrs = append(fieldErrs, field.Forbidden(fieldPath.Child("container"), "container must not be set when s3 is set"))
+	}
+
+	s3Path := fieldPath.Child("s3")
+	if spec.S3.Endpoint != "" {
+		endpoint, err := url.Parse(spec.S3.Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			fieldErrs = append(fieldErrs, field.Invalid(s3Path.Child("endpoint"), spec.S3.Endpoint, "must be an http or https URL"))
+		}
+	}
+	for _, match := range s3KeyTemplatePlaceholderRegexp.FindAllStringSubmatch(spec.S3.KeyTemplate, -1) {
+		if !slices.Contains(S3KeyTemplatePlaceholders, match[1]) {
+			fieldErrs = append(fieldErrs, field.NotSupported(s3Path.Child("keyTemplate"), match[0], S3KeyTemplatePlaceholders))
+		}
+	}
+	if spec.S3.CredentialsSecretRef.Name == "" {
+		fieldErrs = append(fieldErrs, field.Required(s3Path.Child("credentialsSecretRef").Child("name"), "credentials secret must be specified"))
+	}
+	return fieldErrs
+}
@@ -42,7 +42,7 @@ func SetupClusterUploaderWebhookWithManager(mgr ctrl.Manager) error {
 // 1) no new required parameters have been added that are not defined in
 //    existing Pipelines resources that reference it (on update), and
 // 2) no Pipeline resources referring to it exist (on delete).
-// 3) that the user specified an entrypoint to the container.
+// 3) that the user specified an entrypoint to the container, or a valid s3 configuration.
 // +kubebuilder:webhook:path=/validate-ocular-crashoverride-run-v1beta1-clusteruploader,mutating=false,failurePolicy=fail,sideEffects=None,groups=ocular.crashoverride.run,resources=clusteruploaders,verbs=create;update;delete,versions=v1beta1,name=vclusteruploader-v1beta1.ocular.crashoverride.run,admissionReviewVersions=v1
 
 // ClusterUploaderCustomValidator struct is responsible for validating the ClusterUploader resource
@@ -55,7 +55,7 @@ type ClusterUploaderCustomValidator struct {
 func (v *ClusterUploaderCustomValidator) ValidateCreate(ctx context.Context, obj *v1beta1.ClusterUploader) (admission.Warnings, error) {
 	clusteruploaderlog.Info("validating cluster uploader creation", "name", obj.GetName())
 
-	fieldErrs := validators.ValidateContainerDefinition(ctx, field.NewPath("spec").Child("container"), obj.Spec.Container)
+	fieldErrs := validators.ValidateUploaderSpec(ctx, field.NewPath("spec"), obj.Spec)
 
 	if len(fieldErrs) == 0 {
 		return nil, nil
@@ -96,7 +96,7 @@ func (v *ClusterUploaderCustomValidator) ValidateUpdate(ctx context.Context, old
 
 	}
 
-	fieldErrs := validators.ValidateContainerDefinition(ctx, field.NewPath("spec").Child("container"), newUploader.Spec.Container)
+	fieldErrs := validators.ValidateUploaderSpec(ctx, field.NewPath("spec"), newUploader.Spec)
 
 	if len(fieldErrs) == 0 {
 		return nil, nil
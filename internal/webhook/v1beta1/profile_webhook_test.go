@@ -181,6 +181,20 @@ var _ = Describe("Profile Webhook", func() {
 			Expect(validator.ValidateCreate(ctx, obj)).Error().To(Succeed())
 		})
 
+		It("should fail if an artifact check does not reference an artifact", func() {
+			obj.Spec.Artifacts = []string{"results.sarif"}
+			obj.Spec.ArtifactChecks = []v1beta1.ArtifactCheck{
+				{Path: "results.sarif", ContentType: v1beta1.ArtifactContentTypeSARIF},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(Succeed())
+
+			By("checking a path that is not an artifact")
+			obj.Spec.ArtifactChecks = append(obj.Spec.ArtifactChecks, v1beta1.ArtifactCheck{Path: "sbom.json"})
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		})
+
 		It("should fail if referenced uploaders do not exist", func() {
 			By("setting uploader references to non-existent cluster uploader")
 			obj.Spec.UploaderRefs = []v1beta1.ParameterizedLocalObjectReference{
@@ -108,6 +108,10 @@ func validateSearch(ctx context.Context, c client.Client, search *v1beta1.Search
 	crawlerRefPath := field.NewPath("spec").Child("crawlerRef")
 	allErrs = append(allErrs, validators.ValidateParameterReference(ctx, crawlerRefPath, search.Spec.CrawlerRef, crawler.Spec.Parameters)...)
 	allErrs = append(allErrs, validators.ValidateNoParentParameters(field.NewPath("spec").Child("crawlerRef"), search.Spec.CrawlerRef)...)
+	allErrs = append(allErrs, validators.ValidateDefaultPipelineTemplates(search.Spec.Scheduler,
+		field.NewPath("spec").Child("scheduler").Child("defaultPipelineTemplates"))...)
+	allErrs = append(allErrs, validators.ValidatePipelineOverridePolicy(search.Spec.Scheduler.Overrides,
+		field.NewPath("spec").Child("scheduler").Child("overrides"))...)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -45,7 +45,7 @@ func SetupUploaderWebhookWithManager(mgr ctrl.Manager) error {
 // 1) no new required parameters have been added that are not defined in
 //    existing Pipelines resources that reference it (on update), and
 // 2) no Pipeline resources referring to it exist (on delete).
-// 3) ensure that entry point is set for container (on create/update)
+// 3) ensure that entry point is set for container, or the s3 configuration is valid (on create/update)
 // +kubebuilder:webhook:path=/validate-ocular-crashoverride-run-v1beta1-uploader,mutating=false,failurePolicy=fail,sideEffects=None,groups=ocular.crashoverride.run,resources=uploaders,verbs=create;delete;update,versions=v1beta1,name=vuploader-v1beta1.ocular.crashoverride.run,admissionReviewVersions=v1
 
 // UploaderCustomValidator struct is responsible for validating the Uploader resource
@@ -59,7 +59,7 @@ type UploaderCustomValidator struct {
 func (v *UploaderCustomValidator) ValidateCreate(ctx context.Context, uploader *v1beta1.Uploader) (admission.Warnings, error) {
 	uploaderlog.Info("validating uploader creation", "name", uploader.GetName())
 
-	fieldErrs := validators.ValidateContainerDefinition(ctx, field.NewPath("spec").Child("container"), uploader.Spec.Container)
+	fieldErrs := validators.ValidateUploaderSpec(ctx, field.NewPath("spec"), uploader.Spec)
 
 	if len(fieldErrs) == 0 {
 		return nil, nil
@@ -104,7 +104,7 @@ func (v *UploaderCustomValidator) ValidateUpdate(ctx context.Context, oldUploade
 
 	}
 
-	fieldErrs := validators.ValidateContainerDefinition(ctx, field.NewPath("spec").Child("container"), newUploader.Spec.Container)
+	fieldErrs := validators.ValidateUploaderSpec(ctx, field.NewPath("spec"), newUploader.Spec)
 
 	if len(fieldErrs) == 0 {
 		return nil, nil
@@ -9,6 +9,8 @@
 package v1beta1
 
 import (
+	"errors"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
@@ -166,6 +168,34 @@ var _ = Describe("Uploader Webhook", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(apierrors.IsInvalid(err)).To(BeTrue())
 		})
+
+		It("Should allow an S3 uploader without a container", func() {
+			s3Obj := obj.DeepCopy()
+			s3Obj.Spec.Container = v1.Container{}
+			s3Obj.Spec.S3 = &v1beta1.S3UploaderSpec{
+				Bucket:               "results",
+				Endpoint:             "http://minio.minio.svc:9000",
+				ForcePathStyle:       true,
+				KeyTemplate:          "scans/{namespace}/{target}/{version}/{pipeline}",
+				CredentialsSecretRef: v1.LocalObjectReference{Name: "minio-credentials"},
+			}
+			Expect(validator.ValidateCreate(ctx, s3Obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an invalid S3 uploader", func() {
+			s3Obj := obj.DeepCopy()
+			s3Obj.Spec.S3 = &v1beta1.S3UploaderSpec{
+				Bucket:               "results",
+				Endpoint:             "minio:9000",
+				KeyTemplate:          "{namespace}/{unknown}",
+				CredentialsSecretRef: v1.LocalObjectReference{Name: "minio-credentials"},
+			}
+			_, err := validator.ValidateCreate(ctx, s3Obj)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			var statusErr *apierrors.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.ErrStatus.Details.Causes).To(HaveLen(3), "container, endpoint and key template should be invalid")
+		})
 	})
 
 })
@@ -23,6 +23,7 @@ type ApiV1beta1Interface interface {
 	CronSearchesGetter
 	DownloadersGetter
 	PipelinesGetter
+	PipelineQueuesGetter
 	ProfilesGetter
 	SearchesGetter
 	UploadersGetter
@@ -49,6 +50,10 @@ func (c *ApiV1beta1Client) Pipelines(namespace string) PipelineInterface {
 	return newPipelines(c, namespace)
 }
 
+func (c *ApiV1beta1Client) PipelineQueues(namespace string) PipelineQueueInterface {
+	return newPipelineQueues(c, namespace)
+}
+
 func (c *ApiV1beta1Client) Profiles(namespace string) ProfileInterface {
 	return newProfiles(c, namespace)
 }
@@ -35,6 +35,10 @@ func (c *FakeApiV1beta1) Pipelines(namespace string) v1beta1.PipelineInterface {
 	return newFakePipelines(c, namespace)
 }
 
+func (c *FakeApiV1beta1) PipelineQueues(namespace string) v1beta1.PipelineQueueInterface {
+	return newFakePipelineQueues(c, namespace)
+}
+
 func (c *FakeApiV1beta1) Profiles(namespace string) v1beta1.ProfileInterface {
 	return newFakeProfiles(c, namespace)
 }
@@ -17,6 +17,8 @@ type DownloaderExpansion interface{}
 
 type PipelineExpansion interface{}
 
+type PipelineQueueExpansion interface{}
+
 type ProfileExpansion interface{}
 
 type SearchExpansion interface{}
@@ -0,0 +1,449 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+// PipelineRequest is the JSON object written to the pipeline FIFO for each target a crawler emits.
+// The fields of the target are inlined, so a bare [v1beta1.Target] is also a valid request.
+type PipelineRequest struct {
+	v1beta1.Target `json:",inline"`
+
+	// Templates are the names of the pipeline templates of the search to create a pipeline
+	// from for this target. If empty, the default pipeline templates of the search are used.
+	Templates []string `json:"templates,omitempty"`
+
+	// Overrides are the fields of the pipeline template of the search
+	// to replace for the pipeline of this target.
+	Overrides *PipelineOverrides `json:"overrides,omitempty"`
+}
+
+// PipelineOverrides replaces fields of the pipeline template of a search for the pipeline
+// of a single target. The scheduler rejects targets with overrides that are not allowed
+// by the [v1beta1.PipelineOverridePolicy] of the search.
+type PipelineOverrides struct {
+	// ProfileRef replaces the profile of the pipeline template, if set.
+	ProfileRef *v1beta1.ParameterizedLocalObjectReference `json:"profileRef,omitempty"`
+	// DownloaderRef replaces the downloader of the pipeline template, if set.
+	DownloaderRef *v1beta1.ParameterizedLocalObjectReference `json:"downloaderRef,omitempty"`
+	// ProfileParameters are set on the profile reference, replacing any parameters with the same name.
+	ProfileParameters []v1beta1.ParameterSetting `json:"profileParameters,omitempty"`
+	// DownloaderParameters are set on the downloader reference, replacing any parameters with the same name.
+	DownloaderParameters []v1beta1.ParameterSetting `json:"downloaderParameters,omitempty"`
+	// Labels are added to the labels of the pipeline.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the annotations of the pipeline.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ApplyTo replaces the fields of the pipeline that are set in the overrides.
+func (o *PipelineOverrides) ApplyTo(pipeline *v1beta1.Pipeline) {
+	if o == nil {
+		return
+	}
+	if o.ProfileRef != nil {
+		o.ProfileRef.DeepCopyInto(&pipeline.Spec.ProfileRef)
+	}
+	if o.DownloaderRef != nil {
+		o.DownloaderRef.DeepCopyInto(&pipeline.Spec.DownloaderRef)
+	}
+	pipeline.Spec.ProfileRef.Parameters = setParameters(pipeline.Spec.ProfileRef.Parameters, o.ProfileParameters)
+	pipeline.Spec.DownloaderRef.Parameters = setParameters(pipeline.Spec.DownloaderRef.Parameters, o.DownloaderParameters)
+	if len(o.Labels) > 0 && pipeline.Labels == nil {
+		pipeline.Labels = make(map[string]string, len(o.Labels))
+	}
+	maps.Copy(pipeline.Labels, o.Labels)
+	if len(o.Annotations) > 0 && pipeline.Annotations == nil {
+		pipeline.Annotations = make(map[string]string, len(o.Annotations))
+	}
+	maps.Copy(pipeline.Annotations, o.Annotations)
+}
+
+// setParameters returns params with each parameter of overrides
+// replacing the parameter of the same name, or appended if there is none.
+func setParameters(params, overrides []v1beta1.ParameterSetting) []v1beta1.ParameterSetting {
+	for _, override := range overrides {
+		i := slices.IndexFunc(params, func(p v1beta1.ParameterSetting) bool { return p.Name == override.Name })
+		if i < 0 {
+			params = append(params, *override.DeepCopy())
+		} else {
+			override.DeepCopyInto(&params[i])
+		}
+	}
+	return params
+}
+
+const (
+	// defaultCrawlerClientRetries is the number of times an item is retried
+	// when it cannot be written to the scheduler.
+	defaultCrawlerClientRetries = 10
+	// defaultCrawlerClientBackoff is the wait before the first retry,
+	// doubling for every retry up to [maxCrawlerClientBackoff].
+	defaultCrawlerClientBackoff = 100 * time.Millisecond
+	maxCrawlerClientBackoff     = 5 * time.Second
+)
+
+// CrawlerClient emits targets and crawlers to the scheduler of the search the crawler is running in,
+// which creates a pipeline for each target and a search for each crawler. A CrawlerClient is safe for
+// concurrent use, and should be closed once the crawler has emitted everything.
+//
+// A client for the scheduler socket receives the result of each item, returning the name of the created
+// resource or a [*ScheduleError]. A client for the scheduler FIFOs only knows if the item was written.
+type CrawlerClient struct {
+	// socket is set if the client uses the scheduler socket,
+	// otherwise pipelines and searches are used
+	socket    *socketClient
+	pipelines *fifoWriter
+	searches  *fifoWriter
+}
+
+// crawlerClientOptions are the options of a [CrawlerClient].
+type crawlerClientOptions struct {
+	retries int
+	backoff time.Duration
+}
+
+// CrawlerClientOption configures a [CrawlerClient].
+type CrawlerClientOption func(*crawlerClientOptions)
+
+// WithRetries sets the number of times an item is retried when it cannot be sent
+// to the scheduler, and the wait before the first retry, which doubles for every retry.
+// Items sent over the scheduler socket are not retried once the scheduler has received them.
+func WithRetries(retries int, backoff time.Duration) CrawlerClientOption {
+	return func(o *crawlerClientOptions) {
+		o.retries, o.backoff = retries, backoff
+	}
+}
+
+func newCrawlerClientOptions(opts []CrawlerClientOption) crawlerClientOptions {
+	o := crawlerClientOptions{retries: defaultCrawlerClientRetries, backoff: defaultCrawlerClientBackoff}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// NewCrawlerClient returns a [CrawlerClient] that writes to the given pipeline and search FIFOs.
+func NewCrawlerClient(pipelineFIFOPath, searchFIFOPath string, opts ...CrawlerClientOption) *CrawlerClient {
+	o := newCrawlerClientOptions(opts)
+	return &CrawlerClient{
+		pipelines: &fifoWriter{path: pipelineFIFOPath, opts: o},
+		searches:  &fifoWriter{path: searchFIFOPath, opts: o},
+	}
+}
+
+// NewSocketCrawlerClient returns a [CrawlerClient] that sends requests to the scheduler socket at the given path.
+func NewSocketCrawlerClient(socketPath string, opts ...CrawlerClientOption) *CrawlerClient {
+	return &CrawlerClient{
+		socket: &socketClient{path: socketPath, opts: newCrawlerClientOptions(opts)},
+	}
+}
+
+// NewCrawlerClientFromEnvironment returns a [CrawlerClient] for the scheduler socket in the environment
+// variable [v1beta1.EnvVarSchedulerSocket] if set, otherwise for the FIFOs in the environment variables
+// [v1beta1.EnvVarPipelineFIFO] and [v1beta1.EnvVarSearchFIFO]. These are set for crawler containers.
+func NewCrawlerClientFromEnvironment(opts ...CrawlerClientOption) (*CrawlerClient, error) {
+	if socketPath := os.Getenv(v1beta1.EnvVarSchedulerSocket); socketPath != "" {
+		return NewSocketCrawlerClient(socketPath, opts...), nil
+	}
+	pipelineFIFOPath, searchFIFOPath := os.Getenv(v1beta1.EnvVarPipelineFIFO), os.Getenv(v1beta1.EnvVarSearchFIFO)
+	if pipelineFIFOPath == "" || searchFIFOPath == "" {
+		return nil, fmt.Errorf("environment variables %s and %s must be set", v1beta1.EnvVarPipelineFIFO, v1beta1.EnvVarSearchFIFO)
+	}
+	return NewCrawlerClient(pipelineFIFOPath, searchFIFOPath, opts...), nil
+}
+
+// EmitOption configures a single target emitted by [CrawlerClient.EmitTarget].
+type EmitOption func(*PipelineRequest)
+
+// WithTemplates selects the pipeline templates of the search to create a pipeline from
+// for the target, in place of the default templates. Overrides are applied to each pipeline.
+func WithTemplates(names ...string) EmitOption {
+	return func(r *PipelineRequest) {
+		r.Templates = append(r.Templates, names...)
+	}
+}
+
+// WithProfile overrides the profile of the pipeline template for the target.
+func WithProfile(ref v1beta1.ParameterizedLocalObjectReference) EmitOption {
+	return func(r *PipelineRequest) {
+		r.overrides().ProfileRef = &ref
+	}
+}
+
+// WithDownloader overrides the downloader of the pipeline template for the target.
+func WithDownloader(ref v1beta1.ParameterizedLocalObjectReference) EmitOption {
+	return func(r *PipelineRequest) {
+		r.overrides().DownloaderRef = &ref
+	}
+}
+
+// WithProfileParameters sets parameters on the profile reference for the target.
+func WithProfileParameters(params ...v1beta1.ParameterSetting) EmitOption {
+	return func(r *PipelineRequest) {
+		r.overrides().ProfileParameters = append(r.overrides().ProfileParameters, params...)
+	}
+}
+
+// WithDownloaderParameters sets parameters on the downloader reference for the target.
+func WithDownloaderParameters(params ...v1beta1.ParameterSetting) EmitOption {
+	return func(r *PipelineRequest) {
+		r.overrides().DownloaderParameters = append(r.overrides().DownloaderParameters, params...)
+	}
+}
+
+// WithLabel sets a label on the pipeline of the target.
+func WithLabel(key, value string) EmitOption {
+	return func(r *PipelineRequest) {
+		o := r.overrides()
+		if o.Labels == nil {
+			o.Labels = make(map[string]string)
+		}
+		o.Labels[key] = value
+	}
+}
+
+// WithAnnotation sets an annotation on the pipeline of the target.
+func WithAnnotation(key, value string) EmitOption {
+	return func(r *PipelineRequest) {
+		o := r.overrides()
+		if o.Annotations == nil {
+			o.Annotations = make(map[string]string)
+		}
+		o.Annotations[key] = value
+	}
+}
+
+func (r *PipelineRequest) overrides() *PipelineOverrides {
+	if r.Overrides == nil {
+		r.Overrides = &PipelineOverrides{}
+	}
+	return r.Overrides
+}
+
+// EmitTarget sends the target to the scheduler to create a pipeline for it, from the
+// pipeline template of the search with any overrides from opts applied. The name of the
+// created pipeline is returned, which is empty if the client uses the scheduler FIFOs.
+// If the target selects multiple templates with [WithTemplates], the name of the first
+// pipeline is returned, see [CrawlerClient.EmitTargetPipelines] for all of the names.
+func (c *CrawlerClient) EmitTarget(ctx context.Context, target v1beta1.Target, opts ...EmitOption) (string, error) {
+	names, err := c.EmitTargetPipelines(ctx, target, opts...)
+	if len(names) == 0 {
+		return "", err
+	}
+	return names[0], err
+}
+
+// EmitTargetPipelines is the same as [CrawlerClient.EmitTarget], returning the names of the
+// pipelines created for each selected template, in the order selected. A name is empty if the
+// pipeline for that template was not created, and the returned error is for the first of these.
+// No names are returned if the client uses the scheduler FIFOs.
+func (c *CrawlerClient) EmitTargetPipelines(ctx context.Context, target v1beta1.Target, opts ...EmitOption) ([]string, error) {
+	request := PipelineRequest{Target: target}
+	for _, opt := range opts {
+		opt(&request)
+	}
+	if c.socket != nil {
+		response, err := c.socket.schedule(ctx, ScheduleRequest{Pipeline: &request})
+		if len(response.Names) == 0 && response.Name != "" {
+			response.Names = []string{response.Name}
+		}
+		return response.Names, err
+	}
+	return nil, c.pipelines.write(ctx, request)
+}
+
+// EmitSearch sends the crawler to the scheduler to create a search for it, with the same
+// scheduler configuration as the current search. The name of the created search is returned,
+// which is empty if the client uses the scheduler FIFOs.
+func (c *CrawlerClient) EmitSearch(ctx context.Context, crawler v1beta1.ParameterizedLocalObjectReference) (string, error) {
+	if c.socket != nil {
+		response, err := c.socket.schedule(ctx, ScheduleRequest{Search: &crawler})
+		return response.Name, err
+	}
+	return "", c.searches.write(ctx, crawler)
+}
+
+// Close closes the connection to the scheduler, signalling that everything has been emitted.
+func (c *CrawlerClient) Close() error {
+	if c.socket != nil {
+		return c.socket.close()
+	}
+	return errors.Join(c.pipelines.close(), c.searches.close())
+}
+
+// retry calls f until it succeeds, it has been retried the number of times in
+// the options, or the context is done. cleanup is called after each failure.
+func retry(ctx context.Context, opts crawlerClientOptions, f func() error, cleanup func()) error {
+	backoff := opts.backoff
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		cleanup()
+		if attempt >= opts.retries {
+			return fmt.Errorf("failed after %d attempts: %w", attempt+1, err)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxCrawlerClientBackoff)
+	}
+}
+
+// fifoWriter writes JSON values to a FIFO, opening it on the first write
+// and re-opening it if the reader of the FIFO has closed.
+type fifoWriter struct {
+	path string
+	opts crawlerClientOptions
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+func (w *fifoWriter) write(ctx context.Context, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("unable to marshal item: %w", err)
+	}
+	data = append(data, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// the reader may have closed after a failure, so the FIFO must be re-opened
+	err = retry(ctx, w.opts, func() error { return w.tryWrite(data) }, func() { _ = w.closeLocked() })
+	if err != nil {
+		return fmt.Errorf("unable to write to %s: %w", w.path, err)
+	}
+	return nil
+}
+
+func (w *fifoWriter) tryWrite(data []byte) error {
+	if w.f == nil {
+		// opening without blocking fails with ENXIO while the scheduler
+		// has not opened the FIFO for reading, which is retried
+		f, err := os.OpenFile(w.path, os.O_WRONLY|syscall.O_NONBLOCK, os.ModeNamedPipe)
+		if err != nil {
+			return err
+		}
+		w.f = f
+	}
+	_, err := w.f.Write(data)
+	return err
+}
+
+func (w *fifoWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeLocked()
+}
+
+func (w *fifoWriter) closeLocked() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+// socketClient sends [ScheduleRequest] to the scheduler socket, waiting for the response to each.
+type socketClient struct {
+	path string
+	opts crawlerClientOptions
+
+	mu      sync.Mutex
+	conn    net.Conn
+	decoder *json.Decoder
+	nextID  uint64
+}
+
+func (s *socketClient) schedule(ctx context.Context, request ScheduleRequest) (ScheduleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	request.ID = s.nextID
+	data, err := json.Marshal(request)
+	if err != nil {
+		return ScheduleResponse{}, fmt.Errorf("unable to marshal request: %w", err)
+	}
+	data = append(data, '\n')
+
+	err = retry(ctx, s.opts, func() error {
+		if s.conn == nil {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "unix", s.path)
+			if err != nil {
+				return err
+			}
+			s.conn, s.decoder = conn, json.NewDecoder(conn)
+		}
+		_, err := s.conn.Write(data)
+		return err
+	}, func() { _ = s.closeLocked() })
+	if err != nil {
+		return ScheduleResponse{}, fmt.Errorf("unable to send request to %s: %w", s.path, err)
+	}
+
+	// the request may have been handled, so it is not retried if the response is not received.
+	// The connection is captured since s.conn is only accessed while holding the lock.
+	conn := s.conn
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetReadDeadline(time.Now()) })
+	var response ScheduleResponse
+	err = s.decoder.Decode(&response)
+	if !stop() && err == nil {
+		// the context was done after the response was received, so the deadline
+		// may be set once the callback returns, and the connection can't be reused
+		_ = s.closeLocked()
+	}
+	if err != nil {
+		_ = s.closeLocked()
+		return ScheduleResponse{}, errors.Join(ctx.Err(), fmt.Errorf("unable to read response from %s: %w", s.path, err))
+	}
+	if response.ID != request.ID {
+		_ = s.closeLocked()
+		return ScheduleResponse{}, fmt.Errorf("received response %d for request %d", response.ID, request.ID)
+	}
+	if response.Error != nil {
+		return response, response.Error
+	}
+	return response, nil
+}
+
+func (s *socketClient) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
+
+func (s *socketClient) closeLocked() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.decoder = nil, nil
+	return err
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package runtime_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+	"github.com/crashappsec/ocular/pkg/runtime/schedulertest"
+)
+
+func TestCrawlerClient(t *testing.T) {
+	tests := []struct {
+		name string
+		// fifo sets the environment without the scheduler socket
+		fifo          bool
+		expectedNames []string
+	}{
+		{
+			name:          "socket",
+			expectedNames: []string{"pipeline-0", "pipeline-1", "search-2"},
+		},
+		{
+			name:          "fifo",
+			fifo:          true,
+			expectedNames: []string{"", "", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler, err := schedulertest.NewScheduler()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range scheduler.Environment() {
+				if tt.fifo && name == v1beta1.EnvVarSchedulerSocket {
+					continue
+				}
+				t.Setenv(name, value)
+			}
+			client, err := runtime.NewCrawlerClientFromEnvironment()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			goProfile := v1beta1.ParameterizedLocalObjectReference{
+				Name: "go",
+			}
+			crawler := v1beta1.ParameterizedLocalObjectReference{
+				Name:       "crawler",
+				Parameters: []v1beta1.ParameterSetting{{Name: "ORG", Value: "other"}},
+			}
+			var names [3]string
+			if names[0], err = client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "https://github.com/org/a"}); err != nil {
+				t.Fatal(err)
+			}
+			if names[1], err = client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "https://github.com/org/b", Version: "abc"}, runtime.WithProfile(goProfile)); err != nil {
+				t.Fatal(err)
+			}
+			if names[2], err = client.EmitSearch(t.Context(), crawler); err != nil {
+				t.Fatal(err)
+			}
+			if err = client.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err = scheduler.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(names[:], tt.expectedNames) {
+				t.Errorf("expected names %q, got %q", tt.expectedNames, names)
+			}
+			wantTargets := []runtime.PipelineRequest{
+				{Target: v1beta1.Target{Identifier: "https://github.com/org/a"}},
+				{Target: v1beta1.Target{Identifier: "https://github.com/org/b", Version: "abc"}, Overrides: &runtime.PipelineOverrides{ProfileRef: &goProfile}},
+			}
+			if got := scheduler.Targets(); !reflect.DeepEqual(got, wantTargets) {
+				t.Errorf("expected targets %+v, got %+v", wantTargets, got)
+			}
+			if got := scheduler.Searches(); !reflect.DeepEqual(got, []v1beta1.ParameterizedLocalObjectReference{crawler}) {
+				t.Errorf("expected searches %+v, got %+v", crawler, got)
+			}
+		})
+	}
+}
+
+func TestCrawlerClientTemplates(t *testing.T) {
+	scheduler, err := schedulertest.NewScheduler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := scheduler.Client()
+	names, err := client.EmitTargetPipelines(t.Context(), v1beta1.Target{Identifier: "target"}, runtime.WithTemplates("sast", "sbom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"pipeline-0", "pipeline-1"}) {
+		t.Errorf("expected a pipeline for each template, got %q", names)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = scheduler.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if targets := scheduler.Targets(); len(targets) != 1 || !reflect.DeepEqual(targets[0].Templates, []string{"sast", "sbom"}) {
+		t.Errorf("expected target with templates, got %+v", targets)
+	}
+}
+
+func TestCrawlerClientScheduleError(t *testing.T) {
+	scheduler, err := schedulertest.NewScheduler(schedulertest.WithRespond(func(request runtime.ScheduleRequest) runtime.ScheduleResponse {
+		return runtime.ScheduleResponse{
+			Name:  "existing",
+			Error: &runtime.ScheduleError{Reason: runtime.ScheduleErrorReasonDuplicate, Message: "already scanned"},
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := scheduler.Client()
+	name, err := client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "target"})
+	if reason := runtime.ScheduleErrorReasonFor(err); reason != runtime.ScheduleErrorReasonDuplicate {
+		t.Errorf("expected %s error, got %v", runtime.ScheduleErrorReasonDuplicate, err)
+	}
+	if name != "existing" {
+		t.Errorf("expected name of existing pipeline, got %q", name)
+	}
+
+	// the connection remains usable after an error
+	if _, err = client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "other"}); runtime.ScheduleErrorReasonFor(err) == "" {
+		t.Errorf("expected second response from scheduler, got %v", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = scheduler.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(scheduler.Targets()); got != 2 {
+		t.Errorf("expected 2 targets to be received, got %d", got)
+	}
+}
+
+func TestCrawlerClientRetriesUntilSchedulerReads(t *testing.T) {
+	dir := t.TempDir()
+	pipelineFIFOPath := filepath.Join(dir, "pipelines")
+	if err := syscall.Mkfifo(pipelineFIFOPath, 0o622); err != nil {
+		t.Fatal(err)
+	}
+	client := runtime.NewCrawlerClient(pipelineFIFOPath, filepath.Join(dir, "searches"), runtime.WithRetries(20, 10*time.Millisecond))
+
+	emitted := make(chan error)
+	go func() {
+		_, err := client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "target"})
+		emitted <- err
+	}()
+
+	// the scheduler opens the FIFO after the crawler has started emitting
+	time.Sleep(50 * time.Millisecond)
+	f, err := os.OpenFile(pipelineFIFOPath, os.O_RDONLY, os.ModeNamedPipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if err = <-emitted; err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a bare target is decoded the same as a request without overrides
+	var target v1beta1.Target
+	if err = json.NewDecoder(f).Decode(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Identifier != "target" {
+		t.Errorf("expected target to be received, got %+v", target)
+	}
+}
+
+func TestCrawlerClientGivesUp(t *testing.T) {
+	dir := t.TempDir()
+	client := runtime.NewCrawlerClient(filepath.Join(dir, "missing"), filepath.Join(dir, "missing"), runtime.WithRetries(2, time.Millisecond))
+	if _, err := client.EmitTarget(t.Context(), v1beta1.Target{Identifier: "target"}); err == nil {
+		t.Error("expected error when the FIFO does not exist")
+	}
+}
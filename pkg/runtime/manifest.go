@@ -0,0 +1,79 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+// ArtifactKind is the kind of file in an [ArtifactManifest].
+type ArtifactKind string
+
+const (
+	// ArtifactKindArtifact is a file from the artifacts of the profile, written by the scanners.
+	ArtifactKindArtifact ArtifactKind = "artifact"
+	// ArtifactKindMetadata is a file from the metadata files of the downloader.
+	ArtifactKindMetadata ArtifactKind = "metadata"
+)
+
+// ArtifactManifest lists each file to be uploaded for a pipeline, written by the sidecar
+// once all scanners have completed and before any uploader is started. Uploaders can use
+// the manifest in place of the artifact paths passed as arguments, which do not distinguish
+// artifacts from metadata files.
+type ArtifactManifest struct {
+	// Target is the target that was scanned.
+	Target v1beta1.Target `json:"target"`
+	// Artifacts is each file to be uploaded. Artifacts that are directories are
+	// listed as each file within them, and artifacts that failed their check are not listed.
+	Artifacts []ArtifactManifestEntry `json:"artifacts"`
+}
+
+// ArtifactManifestEntry is a single file in an [ArtifactManifest].
+type ArtifactManifestEntry struct {
+	// Path is the absolute path of the file.
+	Path string `json:"path"`
+	// Kind is whether the file is an artifact or a metadata file.
+	Kind ArtifactKind `json:"kind"`
+	// Scanner is the name of the scanner container that produced the file. It is determined
+	// from the time the file was last modified, and is empty if the file was not modified while
+	// exactly one scanner was running.
+	Scanner string `json:"scanner,omitempty"`
+	// SHA256 is the hex encoded SHA-256 digest of the contents of the file.
+	SHA256 string `json:"sha256"`
+	// SizeBytes is the size of the file in bytes.
+	SizeBytes int64 `json:"sizeBytes"`
+}
+
+// GetArtifactManifestFromEnvironment reads the [ArtifactManifest] from the path given by the
+// environment variable [v1beta1.EnvVarArtifactManifestPath]. This is only available
+// to [v1beta1.Uploader] containers.
+func GetArtifactManifestFromEnvironment() (ArtifactManifest, error) {
+	manifestPath := os.Getenv(v1beta1.EnvVarArtifactManifestPath)
+	if manifestPath == "" {
+		return ArtifactManifest{}, fmt.Errorf("environment variable %s is not set", v1beta1.EnvVarArtifactManifestPath)
+	}
+	return ReadArtifactManifest(manifestPath)
+}
+
+// ReadArtifactManifest reads the [ArtifactManifest] from the given path.
+func ReadArtifactManifest(manifestPath string) (ArtifactManifest, error) {
+	contents, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return ArtifactManifest{}, fmt.Errorf("unable to read artifact manifest: %w", err)
+	}
+	var manifest ArtifactManifest
+	if err = json.Unmarshal(contents, &manifest); err != nil {
+		return ArtifactManifest{}, fmt.Errorf("unable to parse artifact manifest: %w", err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+// ScanSummary is the summary of the scanners of a pipeline, written by the sidecar
+// once all scanners have completed and before any uploader is started.
+type ScanSummary struct {
+	// Scanners is the result of each scanner container in the pipeline.
+	Scanners []ScannerResult `json:"scanners"`
+	// Artifacts is the result of checking each artifact passed to the uploader.
+	Artifacts []ArtifactResult `json:"artifacts"`
+}
+
+// ScannerResult is the result of a single scanner container.
+type ScannerResult struct {
+	// Name is the name of the scanner container.
+	Name string `json:"name"`
+	// ExitCode is the exit code of the scanner process.
+	ExitCode int `json:"exitCode"`
+	// StartedAt is the time the scanner process was started,
+	// unset if the scanner did not report it.
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// FinishedAt is the time the scanner process exited,
+	// unset if the scanner did not report it.
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// DurationSeconds is the wall time the scanner process ran for.
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// Succeeded returns true if the scanner exited with a zero exit code.
+func (r ScannerResult) Succeeded() bool {
+	return r.ExitCode == 0
+}
+
+// ArtifactResult is the result of checking an artifact once all scanners have completed.
+// It is the same as the artifact status reported in the status of the pipeline.
+type ArtifactResult = v1beta1.PipelineArtifactStatus
+
+// GetScanSummaryFromEnvironment reads the [ScanSummary] from the path given by the
+// environment variable [v1beta1.EnvVarScanSummaryPath]. This is only available
+// to [v1beta1.Uploader] containers.
+func GetScanSummaryFromEnvironment() (ScanSummary, error) {
+	summaryPath := os.Getenv(v1beta1.EnvVarScanSummaryPath)
+	if summaryPath == "" {
+		return ScanSummary{}, fmt.Errorf("environment variable %s is not set", v1beta1.EnvVarScanSummaryPath)
+	}
+	return ReadScanSummary(summaryPath)
+}
+
+// ReadScanSummary reads the [ScanSummary] from the given path.
+func ReadScanSummary(summaryPath string) (ScanSummary, error) {
+	contents, err := os.ReadFile(summaryPath)
+	if err != nil {
+		return ScanSummary{}, fmt.Errorf("unable to read scan summary: %w", err)
+	}
+	var summary ScanSummary
+	if err = json.Unmarshal(contents, &summary); err != nil {
+		return ScanSummary{}, fmt.Errorf("unable to parse scan summary: %w", err)
+	}
+	return summary, nil
+}
+
+// GetFailedScannersFromEnvironment returns the names of the scanner containers
+// that exited with a non-zero exit code. It is only set for uploaders, which
+// should annotate their results as incomplete if any scanners are returned.
+func GetFailedScannersFromEnvironment() []string {
+	value := os.Getenv(v1beta1.EnvVarFailedScanners)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package runtime
+
+import (
+	"errors"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+// ScheduleRequest is a single line of JSON written to the scheduler socket at
+// [v1beta1.EnvVarSchedulerSocket]. Exactly one of Pipeline or Search must be set.
+// The scheduler writes a [ScheduleResponse] with the same ID once it has handled the request.
+type ScheduleRequest struct {
+	// ID identifies the request in its response.
+	ID uint64 `json:"id"`
+	// Pipeline is a target to create a pipeline for.
+	Pipeline *PipelineRequest `json:"pipeline,omitempty"`
+	// Search is a crawler to create a search for.
+	Search *v1beta1.ParameterizedLocalObjectReference `json:"search,omitempty"`
+}
+
+// ScheduleResponse is the response of the scheduler to a [ScheduleRequest].
+type ScheduleResponse struct {
+	// ID is the ID of the request.
+	ID uint64 `json:"id"`
+	// Name is the name of the created pipeline or search. If the target was skipped
+	// with [ScheduleErrorReasonDuplicate], it is the name of the existing pipeline.
+	Name string `json:"name,omitempty"`
+	// Names are the names of the pipelines for each pipeline template selected by the
+	// target, in the order selected. As with Name, this is the existing pipeline for
+	// duplicates, and empty if the pipeline could not be created. Error is the error
+	// of the first template that failed, and Name the first name. Unset for searches.
+	Names []string `json:"names,omitempty"`
+	// Error is the reason the pipeline or search was not created, unset if it was created.
+	Error *ScheduleError `json:"error,omitempty"`
+}
+
+// ScheduleErrorReason is the reason a request to the scheduler failed. Errors returned by the
+// Kubernetes API when creating the resource use the [k8s.io/apimachinery/pkg/apis/meta/v1.StatusReason]
+// of the error, e.g. Forbidden or Invalid, apart from quota errors which use [ScheduleErrorReasonQuotaExceeded].
+type ScheduleErrorReason string
+
+const (
+	// ScheduleErrorReasonInvalidRequest means the request could not be parsed or was not valid.
+	ScheduleErrorReasonInvalidRequest ScheduleErrorReason = "InvalidRequest"
+	// ScheduleErrorReasonOverrideNotAllowed means the target had overrides
+	// not allowed by the [v1beta1.PipelineOverridePolicy] of the search.
+	ScheduleErrorReasonOverrideNotAllowed ScheduleErrorReason = "OverrideNotAllowed"
+	// ScheduleErrorReasonDuplicate means the target was skipped by the deduplication policy of the search.
+	ScheduleErrorReasonDuplicate ScheduleErrorReason = "Duplicate"
+	// ScheduleErrorReasonQuotaExceeded means the resource would exceed a resource quota of the namespace.
+	ScheduleErrorReasonQuotaExceeded ScheduleErrorReason = "QuotaExceeded"
+	// ScheduleErrorReasonInternalError means the scheduler was unable to create the resource for any other reason.
+	ScheduleErrorReasonInternalError ScheduleErrorReason = "InternalError"
+)
+
+// ScheduleError is the error returned by the scheduler for a request
+// that did not result in a pipeline or search being created.
+type ScheduleError struct {
+	Reason  ScheduleErrorReason `json:"reason"`
+	Message string              `json:"message"`
+}
+
+func (e *ScheduleError) Error() string {
+	return string(e.Reason) + ": " + e.Message
+}
+
+// ScheduleErrorReasonFor returns the reason of the [ScheduleError] in err,
+// or an empty reason if err was not returned by the scheduler.
+func ScheduleErrorReasonFor(err error) ScheduleErrorReason {
+	var scheduleErr *ScheduleError
+	if errors.As(err, &scheduleErr) {
+		return scheduleErr.Reason
+	}
+	return ""
+}
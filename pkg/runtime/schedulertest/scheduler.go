@@ -0,0 +1,264 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+// Package schedulertest provides a fake of the scheduler that runs alongside crawlers,
+// for unit testing crawlers without a cluster.
+package schedulertest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/crashappsec/ocular/pkg/runtime"
+)
+
+// closeSentinel is written to each FIFO by [Scheduler.Close]. It is not valid JSON,
+// so the decoder stops once everything written before it has been read.
+const closeSentinel = "\x00"
+
+// Scheduler is a fake scheduler that records the targets and crawlers written
+// to its FIFOs and socket, in place of creating pipelines and searches.
+type Scheduler struct {
+	// PipelineFIFOPath is the path of the FIFO crawlers write targets to.
+	PipelineFIFOPath string
+	// SearchFIFOPath is the path of the FIFO crawlers write crawler references to.
+	SearchFIFOPath string
+	// SocketPath is the path of the socket crawlers send [runtime.ScheduleRequest] to.
+	SocketPath string
+
+	dir      string
+	respond  func(runtime.ScheduleRequest) runtime.ScheduleResponse
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	targets  []runtime.PipelineRequest
+	searches []v1beta1.ParameterizedLocalObjectReference
+	fifos    []*os.File
+	conns    []net.Conn
+	created  int
+}
+
+// Option configures a [Scheduler].
+type Option func(*Scheduler)
+
+// WithRespond sets the function that returns the response to each request sent to the socket,
+// in place of responding with a generated name. The ID of the response is set by the scheduler.
+// Requests are recorded regardless of the response.
+func WithRespond(respond func(runtime.ScheduleRequest) runtime.ScheduleResponse) Option {
+	return func(s *Scheduler) {
+		s.respond = respond
+	}
+}
+
+// NewScheduler creates the FIFOs and socket of a fake scheduler in a new temporary directory
+// and starts reading from them. The scheduler should be closed once the crawler completes.
+func NewScheduler(opts ...Option) (*Scheduler, error) {
+	dir, err := os.MkdirTemp("", "ocular-scheduler-")
+	if err != nil {
+		return nil, err
+	}
+	s := &Scheduler{
+		PipelineFIFOPath: filepath.Join(dir, "pipelines"),
+		SearchFIFOPath:   filepath.Join(dir, "searches"),
+		SocketPath:       filepath.Join(dir, "scheduler.sock"),
+		dir:              dir,
+	}
+	s.respond = s.generateName
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	pipelines, err := s.open(s.PipelineFIFOPath)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	searches, err := s.open(s.SearchFIFOPath)
+	if err != nil {
+		_ = pipelines.Close()
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	s.listener, err = net.Listen("unix", s.SocketPath)
+	if err != nil {
+		_ = pipelines.Close()
+		_ = searches.Close()
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("unable to listen on socket: %w", err)
+	}
+
+	s.wg.Go(func() {
+		decode(pipelines, func(r runtime.PipelineRequest) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.targets = append(s.targets, r)
+		})
+	})
+	s.wg.Go(func() {
+		decode(searches, func(r v1beta1.ParameterizedLocalObjectReference) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.searches = append(s.searches, r)
+		})
+	})
+	s.wg.Go(s.accept)
+	return s, nil
+}
+
+// open creates the FIFO and opens it for reading and writing, which
+// unlike the scheduler, keeps it open while no crawler is writing to it.
+func (s *Scheduler) open(path string) (*os.File, error) {
+	if err := syscall.Mkfifo(path, 0o622); err != nil {
+		return nil, fmt.Errorf("unable to create FIFO: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, os.ModeNamedPipe)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open FIFO: %w", err)
+	}
+	s.fifos = append(s.fifos, f)
+	return f, nil
+}
+
+func decode[T any](f *os.File, record func(T)) {
+	decoder := json.NewDecoder(f)
+	for {
+		var t T
+		if err := decoder.Decode(&t); err != nil {
+			return
+		}
+		record(t)
+	}
+}
+
+func (s *Scheduler) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Go(func() { s.serve(conn) })
+	}
+}
+
+// serve records the requests of the connection and writes their responses.
+func (s *Scheduler) serve(conn net.Conn) {
+	decoder, encoder := json.NewDecoder(conn), json.NewEncoder(conn)
+	for {
+		var request runtime.ScheduleRequest
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+		s.mu.Lock()
+		if request.Pipeline != nil {
+			s.targets = append(s.targets, *request.Pipeline)
+		}
+		if request.Search != nil {
+			s.searches = append(s.searches, *request.Search)
+		}
+		s.mu.Unlock()
+
+		response := s.respond(request)
+		response.ID = request.ID
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
+
+// generateName is the default response of the scheduler, naming each created resource
+// "pipeline-" or "search-" followed by the number of resources created before it.
+// A pipeline is created for each template selected by a target.
+func (s *Scheduler) generateName(request runtime.ScheduleRequest) runtime.ScheduleResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if request.Pipeline == nil {
+		s.created++
+		return runtime.ScheduleResponse{Name: "search-" + strconv.Itoa(s.created-1)}
+	}
+	names := make([]string, max(len(request.Pipeline.Templates), 1))
+	for i := range names {
+		names[i] = "pipeline-" + strconv.Itoa(s.created)
+		s.created++
+	}
+	return runtime.ScheduleResponse{Name: names[0], Names: names}
+}
+
+// Environment returns the environment variables set for crawlers, pointing to the FIFOs and socket of the scheduler.
+func (s *Scheduler) Environment() map[string]string {
+	return map[string]string{
+		v1beta1.EnvVarPipelineFIFO:    s.PipelineFIFOPath,
+		v1beta1.EnvVarSearchFIFO:      s.SearchFIFOPath,
+		v1beta1.EnvVarSchedulerSocket: s.SocketPath,
+	}
+}
+
+// Client returns a [runtime.CrawlerClient] that sends requests to the socket of the scheduler.
+func (s *Scheduler) Client(opts ...runtime.CrawlerClientOption) *runtime.CrawlerClient {
+	return runtime.NewSocketCrawlerClient(s.SocketPath, opts...)
+}
+
+// FIFOClient returns a [runtime.CrawlerClient] that writes to the FIFOs of the scheduler.
+func (s *Scheduler) FIFOClient(opts ...runtime.CrawlerClientOption) *runtime.CrawlerClient {
+	return runtime.NewCrawlerClient(s.PipelineFIFOPath, s.SearchFIFOPath, opts...)
+}
+
+// Targets returns the targets received by the scheduler, in the order they were received.
+func (s *Scheduler) Targets() []runtime.PipelineRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]runtime.PipelineRequest(nil), s.targets...)
+}
+
+// Searches returns the crawlers received by the scheduler, in the order they were received.
+func (s *Scheduler) Searches() []v1beta1.ParameterizedLocalObjectReference {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]v1beta1.ParameterizedLocalObjectReference(nil), s.searches...)
+}
+
+// Close waits for everything written to the FIFOs and socket to be received,
+// then removes them. It must only be called once crawlers have
+// finished writing, after which [Scheduler.Targets] and [Scheduler.Searches]
+// return everything the crawlers emitted.
+func (s *Scheduler) Close() error {
+	errs := []error{s.listener.Close()}
+	s.mu.Lock()
+	for _, conn := range s.conns {
+		// every request has been responded to, so any connection left open by a crawler is closed
+		errs = append(errs, conn.SetReadDeadline(time.Now()))
+	}
+	s.mu.Unlock()
+	for _, f := range s.fifos {
+		if _, err := f.WriteString(closeSentinel); err != nil {
+			// closing the FIFO stops the decoder instead
+			errs = append(errs, err, f.Close())
+		}
+	}
+	s.wg.Wait()
+	for _, f := range s.fifos {
+		errs = append(errs, f.Close())
+	}
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	errs = append(errs, os.RemoveAll(s.dir))
+	return errors.Join(errs...)
+}